@@ -429,6 +429,7 @@ function map_test_contracts {
         ETHEREUM_NETWORK=$ETHEREUM_NETWORK \
         $REPO_ROOT/deployer map-contracts --timeout "$ORACLE_WAIT_TIME" \
                             --loom-dir "$LOOM_DIR" \
+                            --manifest "$REPO_ROOT/e2e_config/contract_mappings.yml" \
                             --dappchain-contracts "$DAPPCHAIN_CONTRACTS" \
                             --deployment-file "$E2E_CONFIG_DIR/contracts.yml"
     elif [[ "$GATEWAY_TYPE" == "tron-gateway" ]]; then
@@ -439,6 +440,7 @@ function map_test_contracts {
         TRON_NETWORK=$TRON_NETWORK \
         $REPO_ROOT/deployer map-tron-contracts --timeout "$ORACLE_WAIT_TIME" \
                             --loom-dir "$LOOM_DIR" \
+                            --manifest "$REPO_ROOT/e2e_config/contract_mappings.yml" \
                             --dappchain-contracts "$DAPPCHAIN_CONTRACTS" \
                             --deployment-file "$E2E_CONFIG_DIR/contracts.yml"
     elif [[ "$GATEWAY_TYPE" == "binance-gateway" ]]; then
@@ -451,6 +453,7 @@ function map_test_contracts {
         BINANCE_NETWORK=$BINANCE_NETWORK \
         $REPO_ROOT/deployer map-binance-contracts --timeout "$ORACLE_WAIT_TIME" \
                             --loom-dir "$LOOM_DIR" \
+                            --manifest "$REPO_ROOT/e2e_config/contract_mappings.yml" \
                             --contract-dir "$CONTRACT_DIR" \
                             --dappchain-contracts "$DAPPCHAIN_CONTRACTS" \
                             --deployment-file "$E2E_CONFIG_DIR/contracts.yml"
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// The contract wrappers can be connected to any bind.ContractBackend that also implements either
+// TxWaiter or TxReader. *ethclient.Client implements TxReader, while a simulated chain can be
+// wrapped in a SimulatedBackend.
+var (
+	_ TxReader = (*ethclient.Client)(nil)
+	_ TxWaiter = (*SimulatedBackend)(nil)
+)
+
+// TxWaiter is implemented by backends that know how to wait for their own txs to be mined.
+type TxWaiter interface {
+	// WaitForTx should behave like the package level WaitForTx function.
+	WaitForTx(ctx context.Context, tx *types.Transaction) (*types.Receipt, error)
+}
+
+// TxReader is implemented by backends that can look up txs & their receipts, WaitForTx polls these
+// until the tx is mined.
+type TxReader interface {
+	TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// SimulatedChain is the subset of the go-ethereum backends.SimulatedBackend API used by
+// SimulatedBackend.
+type SimulatedChain interface {
+	bind.ContractBackend
+	Commit()
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// SimulatedBackend wraps an in-memory chain, such as the one created by
+// backends.NewSimulatedBackend, and mines a new block every time it has to wait for a tx, so the
+// contract wrappers work the same way against it as against a real node.
+type SimulatedBackend struct {
+	SimulatedChain
+}
+
+// NewSimulatedBackend wraps the given simulated chain.
+func NewSimulatedBackend(chain SimulatedChain) *SimulatedBackend {
+	return &SimulatedBackend{SimulatedChain: chain}
+}
+
+// WaitForTx mines any pending txs and returns the receipt of the given tx.
+func (b *SimulatedBackend) WaitForTx(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	if ctx.Err() != nil {
+		return nil, &TxTimeoutError{TxHash: tx.Hash(), Err: ctx.Err()}
+	}
+	b.Commit()
+	receipt, err := b.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	// The simulated chain returns a nil receipt rather than an error for unknown txs
+	if receipt == nil {
+		return nil, &TxDroppedError{TxHash: tx.Hash()}
+	}
+	return checkReceipt(tx.Hash(), receipt)
+}
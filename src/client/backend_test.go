@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/loomnetwork/go-loom/client"
+	"github.com/stretchr/testify/require"
+)
+
+// Wei given to each test account in the genesis block.
+var testAccountBalance = new(big.Int).Exp(big.NewInt(10), big.NewInt(21), nil)
+
+func newTestIdentity(t *testing.T) *client.Identity {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return &client.Identity{MainnetPrivKey: key, MainnetAddr: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Creates a simulated chain where each of the given accounts has testAccountBalance wei.
+func newTestBackend(accounts ...*client.Identity) *SimulatedBackend {
+	alloc := core.GenesisAlloc{}
+	for _, account := range accounts {
+		alloc[account.MainnetAddr] = core.GenesisAccount{Balance: testAccountBalance}
+	}
+	return NewSimulatedBackend(backends.NewSimulatedBackend(alloc, 8000000))
+}
+
+func TestDeployMainnetERC20Contract(t *testing.T) {
+	creator := newTestIdentity(t)
+	backend := newTestBackend(creator)
+
+	token, err := DeployMainnetERC20Contract(backend, creator, common.Address{})
+	require.NoError(t, err)
+	require.NotEqual(t, common.Address{}, token.Address)
+
+	receipt, err := backend.TransactionReceipt(context.Background(), common.HexToHash(token.TxHash))
+	require.NoError(t, err)
+	require.Equal(t, token.Address, receipt.ContractAddress)
+
+	// The whole supply of one billion tokens with 18 decimals goes to the creator
+	balance, err := token.BalanceOf(creator)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil).String(), balance.String())
+}
+
+func TestMainnetERC20TransferTo(t *testing.T) {
+	alice := newTestIdentity(t)
+	bob := newTestIdentity(t)
+	backend := newTestBackend(alice, bob)
+	token, err := DeployMainnetERC20Contract(backend, alice, common.Address{})
+	require.NoError(t, err)
+	aliceBalance, err := token.BalanceOf(alice)
+	require.NoError(t, err)
+
+	amount := big.NewInt(1000)
+	result, err := token.TransferTo(alice, bob.MainnetAddr, amount)
+	require.NoError(t, err)
+	require.NotZero(t, result.GasUsed)
+	require.Equal(t, new(big.Int).Mul(new(big.Int).SetUint64(result.GasUsed), result.EffectiveGasPrice).String(), result.Cost().String())
+
+	ev := result.Event("Transfer")
+	require.NotNil(t, ev)
+	require.Equal(t, alice.MainnetAddr, ev.Args["from"])
+	require.Equal(t, bob.MainnetAddr, ev.Args["to"])
+	require.Equal(t, amount.String(), fmt.Sprint(ev.Args["value"]))
+
+	balance, err := token.BalanceOf(bob)
+	require.NoError(t, err)
+	require.Equal(t, amount.String(), balance.String())
+	balance, err = token.BalanceOf(alice)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Sub(aliceBalance, amount).String(), balance.String())
+
+	// Transfer sends the tokens from the second identity to the first
+	_, err = token.Transfer(alice, bob, amount)
+	require.NoError(t, err)
+	balance, err = token.BalanceOf(bob)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+}
+
+func TestMainnetCardsDepositToGateway(t *testing.T) {
+	creator := newTestIdentity(t)
+	alice := newTestIdentity(t)
+	backend := newTestBackend(creator, alice)
+	// The cards contract transfers deposited tokens to the Gateway, which doesn't have to be a
+	// contract for that to work.
+	gatewayAddr := newTestIdentity(t).MainnetAddr
+	cards, err := DeployMainnetCardsContract(backend, creator, gatewayAddr)
+	require.NoError(t, err)
+
+	_, err = cards.MintTokens(creator, alice)
+	require.NoError(t, err)
+	balance, err := cards.BalanceOf(alice)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), balance)
+
+	tokenID, err := cards.TokenOfOwnerByIndex(alice, 0)
+	require.NoError(t, err)
+	result, err := cards.DepositToGateway(alice, tokenID)
+	require.NoError(t, err)
+	ev := result.Event("Transfer")
+	require.NotNil(t, ev)
+	require.Equal(t, gatewayAddr, ev.Args["to"])
+	require.Equal(t, tokenID.String(), fmt.Sprint(ev.Args["tokenId"]))
+
+	owner, err := cards.OwnerOf(tokenID)
+	require.NoError(t, err)
+	require.Equal(t, gatewayAddr, owner)
+	balance, err = cards.BalanceOf(alice)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), balance)
+}
+
+func TestMainnetERC721XDepositToGateway(t *testing.T) {
+	creator := newTestIdentity(t)
+	alice := newTestIdentity(t)
+	backend := newTestBackend(creator, alice)
+	gatewayAddr := newTestIdentity(t).MainnetAddr
+	token, err := DeployMainnetERC721XContract(backend, creator, gatewayAddr)
+	require.NoError(t, err)
+
+	tokenID := big.NewInt(1)
+	_, err = token.MintTokens(creator, tokenID, big.NewInt(10), alice)
+	require.NoError(t, err)
+	_, err = token.DepositToGateway(alice, tokenID, big.NewInt(4))
+	require.NoError(t, err)
+
+	balance, err := token.TokenBalance(gatewayAddr, tokenID)
+	require.NoError(t, err)
+	require.Equal(t, "4", balance.String())
+	balance, err = token.BalanceOf(alice, tokenID)
+	require.NoError(t, err)
+	require.Equal(t, "6", balance.String())
+}
+
+func TestSimulatedBackendWaitForTxReverted(t *testing.T) {
+	alice := newTestIdentity(t)
+	backend := newTestBackend(alice)
+	token, err := DeployMainnetERC20Contract(backend, alice, common.Address{})
+	require.NoError(t, err)
+
+	// Set the gas limit so the tx is sent without estimating the gas, which would fail since the
+	// transfer exceeds the token balance of the sender.
+	opts := transactOpts(context.Background(), alice)
+	opts.GasLimit = 100000
+	tx, err := token.contract.Transfer(opts, alice.MainnetAddr, new(big.Int).Mul(testAccountBalance, testAccountBalance))
+	require.NoError(t, err)
+	receipt, err := WaitForTx(context.Background(), backend, tx)
+	require.IsType(t, &TxRevertedError{}, err)
+	require.NotNil(t, receipt)
+}
@@ -6,38 +6,67 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/loomnetwork/go-loom/client"
 )
 
+var mainnetCryptoCardsABI = mustParseABI(ethcontract.MainnetCryptoCardsContractABI)
+
 type MainnetCryptoCardsClient struct {
-	contract  *ethcontract.MainnetCryptoCardsContract
-	ethClient *ethclient.Client
+	contract *ethcontract.MainnetCryptoCardsContract
+	backend  bind.ContractBackend
 
 	TxTimeout time.Duration
 	Address   common.Address
 	TxHash    string
+	// Assigns the nonces of the txs sent through the contract, the node assigns them when nil
+	Nonces *NonceManager
+}
+
+func (c *MainnetCryptoCardsClient) MintTokens(contractOwner *client.Identity, recipient *client.Identity) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.MintTokensContext(ctx, contractOwner, recipient)
 }
 
-func (c *MainnetCryptoCardsClient) MintTokens(contractOwner *client.Identity, recipient *client.Identity) error {
-	tx, err := c.contract.MintTokens(client.DefaultTransactOptsForIdentity(contractOwner), recipient.MainnetAddr)
+func (c *MainnetCryptoCardsClient) MintTokensContext(
+	ctx context.Context, contractOwner *client.Identity, recipient *client.Identity,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, contractOwner, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.MintTokens(opts, recipient.MainnetAddr)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetCryptoCardsABI)
 }
 
-func (c *MainnetCryptoCardsClient) DepositToGateway(caller *client.Identity, tokenID *big.Int) error {
-	tx, err := c.contract.DepositToGateway(client.DefaultTransactOptsForIdentity(caller), tokenID)
+func (c *MainnetCryptoCardsClient) DepositToGateway(caller *client.Identity, tokenID *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.DepositToGatewayContext(ctx, caller, tokenID)
+}
+
+func (c *MainnetCryptoCardsClient) DepositToGatewayContext(ctx context.Context, caller *client.Identity, tokenID *big.Int) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.DepositToGateway(opts, tokenID)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetCryptoCardsABI)
 }
 
 func (c *MainnetCryptoCardsClient) BalanceOf(caller *client.Identity) (uint64, error) {
-	bal, err := c.contract.BalanceOf(nil, caller.MainnetAddr)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceOfContext(ctx, caller)
+}
+
+func (c *MainnetCryptoCardsClient) BalanceOfContext(ctx context.Context, caller *client.Identity) (uint64, error) {
+	bal, err := c.BalanceContext(ctx, caller.MainnetAddr)
 	if err != nil {
 		return 0, err
 	}
@@ -45,48 +74,108 @@ func (c *MainnetCryptoCardsClient) BalanceOf(caller *client.Identity) (uint64, e
 }
 
 func (c *MainnetCryptoCardsClient) TokenOfOwnerByIndex(caller *client.Identity, index int) (*big.Int, error) {
-	tokenID, err := c.contract.TokenOfOwnerByIndex(nil, caller.MainnetAddr, new(big.Int).SetInt64(int64(index)))
-	if err != nil {
-		return nil, err
-	}
-	return tokenID, nil
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TokenOfOwnerByIndexContext(ctx, caller, index)
+}
+
+func (c *MainnetCryptoCardsClient) TokenOfOwnerByIndexContext(
+	ctx context.Context, caller *client.Identity, index int,
+) (*big.Int, error) {
+	return c.contract.TokenOfOwnerByIndex(callOpts(ctx), caller.MainnetAddr, new(big.Int).SetInt64(int64(index)))
 }
 
 func (c *MainnetCryptoCardsClient) OwnerOf(tokenID *big.Int) (common.Address, error) {
-	return c.contract.OwnerOf(nil, tokenID)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.OwnerOfContext(ctx, tokenID)
+}
+
+func (c *MainnetCryptoCardsClient) OwnerOfContext(ctx context.Context, tokenID *big.Int) (common.Address, error) {
+	return c.contract.OwnerOf(callOpts(ctx), tokenID)
 }
 
-func ConnectToMainnetCards(ethClient *ethclient.Client, address string) (*MainnetCryptoCardsClient, error) {
+func (c *MainnetCryptoCardsClient) TokenAddress() common.Address {
+	return c.Address
+}
+
+func (c *MainnetCryptoCardsClient) Balance(owner common.Address) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceContext(ctx, owner)
+}
+
+func (c *MainnetCryptoCardsClient) BalanceContext(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return c.contract.BalanceOf(callOpts(ctx), owner)
+}
+
+// SafeTransferFrom transfers a token owned by the caller, the Gateway treats a transfer to it as a
+// deposit.
+func (c *MainnetCryptoCardsClient) SafeTransferFrom(caller *client.Identity, to common.Address, tokenID *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.SafeTransferFromContext(ctx, caller, to, tokenID)
+}
+
+func (c *MainnetCryptoCardsClient) SafeTransferFromContext(
+	ctx context.Context, caller *client.Identity, to common.Address, tokenID *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.SafeTransferFrom(opts, caller.MainnetAddr, to, tokenID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waitForTxResult(ctx, c.backend, tx, mainnetCryptoCardsABI)
+}
+
+func ConnectToMainnetCards(backend bind.ContractBackend, address string) (*MainnetCryptoCardsClient, error) {
 	contractAddr := common.HexToAddress(address)
-	contract, err := ethcontract.NewMainnetCryptoCardsContract(contractAddr, ethClient)
+	contract, err := ethcontract.NewMainnetCryptoCardsContract(contractAddr, backend)
 	if err != nil {
 		return nil, err
 	}
 	return &MainnetCryptoCardsClient{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   contractAddr,
 	}, nil
 }
 
 func DeployMainnetCardsContract(
-	ethClient *ethclient.Client, creator *client.Identity, gatewayAddr common.Address,
+	backend bind.ContractBackend, creator *client.Identity, gatewayAddr common.Address,
+) (*MainnetCryptoCardsClient, error) {
+	ctx, cancel := txContext(DefaultTxTimeout)
+	defer cancel()
+	return DeployMainnetCardsContractContext(ctx, backend, nil, creator, gatewayAddr)
+}
+
+// DeployMainnetCardsContractContext sends the deploy tx using the given nonce manager, which is
+// also used by the returned wrapper, or leaves nonce assignment to the node if it's nil.
+func DeployMainnetCardsContractContext(
+	ctx context.Context, backend bind.ContractBackend, nonces *NonceManager, creator *client.Identity,
+	gatewayAddr common.Address,
 ) (*MainnetCryptoCardsClient, error) {
-	addr, tx, contract, err := ethcontract.DeployMainnetCryptoCardsContract(
-		client.DefaultTransactOptsForIdentity(creator),
-		ethClient,
-		gatewayAddr,
-	)
+	var addr common.Address
+	var contract *ethcontract.MainnetCryptoCardsContract
+	tx, err := sendTx(ctx, nonces, creator, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		deployedAddr, tx, deployed, err := ethcontract.DeployMainnetCryptoCardsContract(opts, backend, gatewayAddr)
+		addr, contract = deployedAddr, deployed
+		return tx, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := client.WaitForTxConfirmation(context.TODO(), ethClient, tx, 0); err != nil {
+	if _, err := WaitForTx(ctx, backend, tx); err != nil {
 		return nil, err
 	}
 	return &MainnetCryptoCardsClient{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   addr,
 		TxHash:    tx.Hash().Hex(),
+		Nonces:    nonces,
 	}, nil
 }
@@ -6,96 +6,183 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/loomnetwork/go-loom/client"
 )
 
+var mainnetERC20ABI = mustParseABI(ethcontract.MainnetGameTokenContractABI)
+
 type MainnetERC20Contract struct {
-	contract  *ethcontract.MainnetGameTokenContract
-	ethClient *ethclient.Client
+	contract *ethcontract.MainnetGameTokenContract
+	backend  bind.ContractBackend
 
 	TxTimeout time.Duration
 	Address   common.Address
 	TxHash    string
+	// Assigns the nonces of the txs sent through the contract, the node assigns them when nil
+	Nonces *NonceManager
 }
 
 func (c *MainnetERC20Contract) BalanceOf(caller *client.Identity) (*big.Int, error) {
-	bal, err := c.contract.BalanceOf(nil, caller.MainnetAddr)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceOfContext(ctx, caller)
+}
+
+func (c *MainnetERC20Contract) BalanceOfContext(ctx context.Context, caller *client.Identity) (*big.Int, error) {
+	return c.BalanceContext(ctx, caller.MainnetAddr)
+}
+
+func (c *MainnetERC20Contract) TransferFrom(to *client.Identity, from *client.Identity, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferFromContext(ctx, to, from, amount)
+}
+
+func (c *MainnetERC20Contract) TransferFromContext(
+	ctx context.Context, to *client.Identity, from *client.Identity, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return c.contract.TransferFrom(opts, from.MainnetAddr, to.MainnetAddr, amount)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return bal, nil
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20ABI)
 }
 
-func (c *MainnetERC20Contract) TransferFrom(to *client.Identity, from *client.Identity, amount *big.Int) error {
-	tx, err := c.contract.TransferFrom(client.DefaultTransactOptsForIdentity(from), from.MainnetAddr, to.MainnetAddr, amount)
-	if err != nil {
-		return err
-	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+func (c *MainnetERC20Contract) Approve(from *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.ApproveContext(ctx, from, to, amount)
 }
 
-func (c *MainnetERC20Contract) Approve(from *client.Identity, to common.Address, amount *big.Int) error {
-	tx, err := c.contract.Approve(client.DefaultTransactOptsForIdentity(from), to, amount)
+func (c *MainnetERC20Contract) ApproveContext(ctx context.Context, from *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return c.contract.Approve(opts, to, amount)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20ABI)
 }
 
-func (c *MainnetERC20Contract) Transfer(to *client.Identity, from *client.Identity, amount *big.Int) error {
-	tx, err := c.contract.Transfer(client.DefaultTransactOptsForIdentity(from), to.MainnetAddr, amount)
-	if err != nil {
-		return err
-	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+func (c *MainnetERC20Contract) Transfer(to *client.Identity, from *client.Identity, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferContext(ctx, to, from, amount)
+}
+
+func (c *MainnetERC20Contract) TransferContext(
+	ctx context.Context, to *client.Identity, from *client.Identity, amount *big.Int,
+) (*TxResult, error) {
+	return c.TransferToContext(ctx, from, to.MainnetAddr, amount)
 }
 
 // TransferTx calls  Transfer and waits for it to complete. It returns tx and error.
 func (c *MainnetERC20Contract) TransferTx(caller *client.Identity, to common.Address, amount *big.Int) (*ethtypes.Transaction, error) {
-	tx, err := c.contract.Transfer(client.DefaultTransactOptsForIdentity(caller), to, amount)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferTxContext(ctx, caller, to, amount)
+}
+
+func (c *MainnetERC20Contract) TransferTxContext(
+	ctx context.Context, caller *client.Identity, to common.Address, amount *big.Int,
+) (*ethtypes.Transaction, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return c.contract.Transfer(opts, to, amount)
+	})
 	if err != nil {
 		return nil, err
 	}
-	err = client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
-	if err != nil {
+	if _, err := WaitForTx(ctx, c.backend, tx); err != nil {
 		return nil, err
 	}
 	return tx, nil
 }
 
-func ConnectToMainnetERC20Contract(ethClient *ethclient.Client, address string) (*MainnetERC20Contract, error) {
+func (c *MainnetERC20Contract) TokenAddress() common.Address {
+	return c.Address
+}
+
+func (c *MainnetERC20Contract) Balance(owner common.Address) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceContext(ctx, owner)
+}
+
+func (c *MainnetERC20Contract) BalanceContext(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return c.contract.BalanceOf(callOpts(ctx), owner)
+}
+
+// TransferTo transfers tokens owned by the caller to the given address.
+func (c *MainnetERC20Contract) TransferTo(caller *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferToContext(ctx, caller, to, amount)
+}
+
+func (c *MainnetERC20Contract) TransferToContext(
+	ctx context.Context, caller *client.Identity, to common.Address, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return c.contract.Transfer(opts, to, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20ABI)
+}
+
+func ConnectToMainnetERC20Contract(backend bind.ContractBackend, address string) (*MainnetERC20Contract, error) {
 	contractAddr := common.HexToAddress(address)
-	contract, err := ethcontract.NewMainnetGameTokenContract(contractAddr, ethClient)
+	contract, err := ethcontract.NewMainnetGameTokenContract(contractAddr, backend)
 	if err != nil {
 		return nil, err
 	}
 	return &MainnetERC20Contract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   contractAddr,
 	}, nil
 }
 
 func DeployMainnetERC20Contract(
-	ethClient *ethclient.Client, creator *client.Identity, gatewayAddr common.Address,
+	backend bind.ContractBackend, creator *client.Identity, gatewayAddr common.Address,
+) (*MainnetERC20Contract, error) {
+	ctx, cancel := txContext(DefaultTxTimeout)
+	defer cancel()
+	return DeployMainnetERC20ContractContext(ctx, backend, nil, creator, gatewayAddr)
+}
+
+// DeployMainnetERC20ContractContext sends the deploy tx using the given nonce manager, which is
+// also used by the returned wrapper, or leaves nonce assignment to the node if it's nil.
+func DeployMainnetERC20ContractContext(
+	ctx context.Context, backend bind.ContractBackend, nonces *NonceManager, creator *client.Identity,
+	gatewayAddr common.Address,
 ) (*MainnetERC20Contract, error) {
-	addr, tx, contract, err := ethcontract.DeployMainnetGameTokenContract(
-		client.DefaultTransactOptsForIdentity(creator),
-		ethClient,
-	)
+	var addr common.Address
+	var contract *ethcontract.MainnetGameTokenContract
+	tx, err := sendTx(ctx, nonces, creator, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		deployedAddr, tx, deployed, err := ethcontract.DeployMainnetGameTokenContract(opts, backend)
+		addr, contract = deployedAddr, deployed
+		return tx, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := client.WaitForTxConfirmation(context.TODO(), ethClient, tx, 0); err != nil {
+	if _, err := WaitForTx(ctx, backend, tx); err != nil {
 		return nil, err
 	}
 	return &MainnetERC20Contract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   addr,
 		TxHash:    tx.Hash().Hex(),
+		Nonces:    nonces,
 	}, nil
 }
@@ -6,99 +6,199 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/loomnetwork/go-loom/client"
 )
 
+var mainnetERC20MintableABI = mustParseABI(ethcontract.SampleERC20MintableTokenABI)
+
 type MainnetERC20MintableContract struct {
-	contract  *ethcontract.SampleERC20MintableToken
-	ethClient *ethclient.Client
+	contract *ethcontract.SampleERC20MintableToken
+	backend  bind.ContractBackend
 
 	TxTimeout time.Duration
 	Address   common.Address
 	TxHash    string
+	// Assigns the nonces of the txs sent through the contract, the node assigns them when nil
+	Nonces *NonceManager
 }
 
 func (c *MainnetERC20MintableContract) BalanceOf(caller *client.Identity) (*big.Int, error) {
-	bal, err := c.contract.BalanceOf(nil, caller.MainnetAddr)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceOfContext(ctx, caller)
+}
+
+func (c *MainnetERC20MintableContract) BalanceOfContext(ctx context.Context, caller *client.Identity) (*big.Int, error) {
+	return c.BalanceContext(ctx, caller.MainnetAddr)
+}
+
+func (c *MainnetERC20MintableContract) TransferFrom(to *client.Identity, from *client.Identity, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferFromContext(ctx, to, from, amount)
+}
+
+func (c *MainnetERC20MintableContract) TransferFromContext(
+	ctx context.Context, to *client.Identity, from *client.Identity, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.TransferFrom(opts, from.MainnetAddr, to.MainnetAddr, amount)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return bal, nil
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20MintableABI)
 }
 
-func (c *MainnetERC20MintableContract) TransferFrom(to *client.Identity, from *client.Identity, amount *big.Int) error {
-	tx, err := c.contract.TransferFrom(client.DefaultTransactOptsForIdentity(from), from.MainnetAddr, to.MainnetAddr, amount)
-	if err != nil {
-		return err
-	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+func (c *MainnetERC20MintableContract) Approve(from *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.ApproveContext(ctx, from, to, amount)
 }
 
-func (c *MainnetERC20MintableContract) Approve(from *client.Identity, to common.Address, amount *big.Int) error {
-	tx, err := c.contract.Approve(client.DefaultTransactOptsForIdentity(from), to, amount)
+func (c *MainnetERC20MintableContract) ApproveContext(
+	ctx context.Context, from *client.Identity, to common.Address, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Approve(opts, to, amount)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20MintableABI)
+}
+
+func (c *MainnetERC20MintableContract) Mint(from *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.MintContext(ctx, from, to, amount)
 }
 
-func (c *MainnetERC20MintableContract) Mint(from *client.Identity, to common.Address, amount *big.Int) error {
-	tx, err := c.contract.Mint(client.DefaultTransactOptsForIdentity(from), to, amount)
+func (c *MainnetERC20MintableContract) MintContext(
+	ctx context.Context, from *client.Identity, to common.Address, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Mint(opts, to, amount)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20MintableABI)
+}
+
+func (c *MainnetERC20MintableContract) MintTo(from *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.MintToContext(ctx, from, to, amount)
 }
 
-func (c *MainnetERC20MintableContract) MintTo(from *client.Identity, to common.Address, amount *big.Int) error {
-	tx, err := c.contract.MintTo(client.DefaultTransactOptsForIdentity(from), to, amount)
+func (c *MainnetERC20MintableContract) MintToContext(
+	ctx context.Context, from *client.Identity, to common.Address, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.MintTo(opts, to, amount)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20MintableABI)
 }
 
-func (c *MainnetERC20MintableContract) Transfer(to *client.Identity, from *client.Identity, amount *big.Int) error {
-	tx, err := c.contract.Transfer(client.DefaultTransactOptsForIdentity(from), to.MainnetAddr, amount)
+func (c *MainnetERC20MintableContract) Transfer(to *client.Identity, from *client.Identity, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferContext(ctx, to, from, amount)
+}
+
+func (c *MainnetERC20MintableContract) TransferContext(
+	ctx context.Context, to *client.Identity, from *client.Identity, amount *big.Int,
+) (*TxResult, error) {
+	return c.TransferToContext(ctx, from, to.MainnetAddr, amount)
+}
+
+func (c *MainnetERC20MintableContract) TokenAddress() common.Address {
+	return c.Address
+}
+
+func (c *MainnetERC20MintableContract) Balance(owner common.Address) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceContext(ctx, owner)
+}
+
+func (c *MainnetERC20MintableContract) BalanceContext(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return c.contract.BalanceOf(callOpts(ctx), owner)
+}
+
+// TransferTo transfers tokens owned by the caller to the given address.
+func (c *MainnetERC20MintableContract) TransferTo(caller *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferToContext(ctx, caller, to, amount)
+}
+
+func (c *MainnetERC20MintableContract) TransferToContext(
+	ctx context.Context, caller *client.Identity, to common.Address, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Transfer(opts, to, amount)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC20MintableABI)
 }
 
-func ConnectToMainnetERC20MintableContract(ethClient *ethclient.Client, address string) (*MainnetERC20MintableContract, error) {
+func ConnectToMainnetERC20MintableContract(backend bind.ContractBackend, address string) (*MainnetERC20MintableContract, error) {
 	contractAddr := common.HexToAddress(address)
-	contract, err := ethcontract.NewSampleERC20MintableToken(contractAddr, ethClient)
+	contract, err := ethcontract.NewSampleERC20MintableToken(contractAddr, backend)
 	if err != nil {
 		return nil, err
 	}
 	return &MainnetERC20MintableContract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   contractAddr,
 	}, nil
 }
 
 func DeployMainnetERC20MintableContract(
-	ethClient *ethclient.Client, creator *client.Identity, gatewayAddr common.Address,
+	backend bind.ContractBackend, creator *client.Identity, gatewayAddr common.Address,
+) (*MainnetERC20MintableContract, error) {
+	ctx, cancel := txContext(DefaultTxTimeout)
+	defer cancel()
+	return DeployMainnetERC20MintableContractContext(ctx, backend, nil, creator, gatewayAddr)
+}
+
+// DeployMainnetERC20MintableContractContext sends the deploy tx using the given nonce manager,
+// which is also used by the returned wrapper, or leaves nonce assignment to the node if it's nil.
+func DeployMainnetERC20MintableContractContext(
+	ctx context.Context, backend bind.ContractBackend, nonces *NonceManager, creator *client.Identity,
+	gatewayAddr common.Address,
 ) (*MainnetERC20MintableContract, error) {
-	addr, tx, contract, err := ethcontract.DeploySampleERC20MintableToken(
-		client.DefaultTransactOptsForIdentity(creator),
-		ethClient,
-		gatewayAddr,
-	)
+	var addr common.Address
+	var contract *ethcontract.SampleERC20MintableToken
+	tx, err := sendTx(ctx, nonces, creator, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		deployedAddr, tx, deployed, err := ethcontract.DeploySampleERC20MintableToken(opts, backend, gatewayAddr)
+		addr, contract = deployedAddr, deployed
+		return tx, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := client.WaitForTxConfirmation(context.TODO(), ethClient, tx, 0); err != nil {
+	if _, err := WaitForTx(ctx, backend, tx); err != nil {
 		return nil, err
 	}
 	return &MainnetERC20MintableContract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   addr,
 		TxHash:    tx.Hash().Hex(),
+		Nonces:    nonces,
 	}, nil
 }
@@ -6,79 +6,162 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/loomnetwork/go-loom/client"
 )
 
+var mainnetERC721MintableABI = mustParseABI(ethcontract.SampleERC721MintableTokenABI)
+
 type MainnetERC721MintableContract struct {
-	contract  *ethcontract.SampleERC721MintableToken
-	ethClient *ethclient.Client
+	contract *ethcontract.SampleERC721MintableToken
+	backend  bind.ContractBackend
 
 	TxTimeout time.Duration
 	Address   common.Address
 	TxHash    string
+	// Assigns the nonces of the txs sent through the contract, the node assigns them when nil
+	Nonces *NonceManager
+}
+
+func (c *MainnetERC721MintableContract) Mint(from *client.Identity, to common.Address, tokenID *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.MintContext(ctx, from, to, tokenID)
 }
 
-func (c *MainnetERC721MintableContract) Mint(from *client.Identity, to common.Address, tokenID *big.Int) error {
-	tx, err := c.contract.Mint(client.DefaultTransactOptsForIdentity(from), to, tokenID)
+func (c *MainnetERC721MintableContract) MintContext(
+	ctx context.Context, from *client.Identity, to common.Address, tokenID *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Mint(opts, to, tokenID)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC721MintableABI)
 }
 
-func (c *MainnetERC721MintableContract) MintTo(from *client.Identity, to common.Address, tokenID *big.Int) error {
-	tx, err := c.contract.MintTo(client.DefaultTransactOptsForIdentity(from), to, tokenID)
+func (c *MainnetERC721MintableContract) MintTo(from *client.Identity, to common.Address, tokenID *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.MintToContext(ctx, from, to, tokenID)
+}
+
+func (c *MainnetERC721MintableContract) MintToContext(
+	ctx context.Context, from *client.Identity, to common.Address, tokenID *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, from, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.MintTo(opts, to, tokenID)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC721MintableABI)
 }
 
-func (c *MainnetERC721MintableContract) BalanceOf(caller *client.Identity) (*big.Int, error) {
-	bal, err := c.contract.BalanceOf(nil, caller.MainnetAddr)
+// SafeTransferFrom transfers a token owned by the caller, the Gateway treats a transfer to it as a
+// deposit.
+func (c *MainnetERC721MintableContract) SafeTransferFrom(caller *client.Identity, to common.Address, tokenID *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.SafeTransferFromContext(ctx, caller, to, tokenID)
+}
+
+func (c *MainnetERC721MintableContract) SafeTransferFromContext(
+	ctx context.Context, caller *client.Identity, to common.Address, tokenID *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.SafeTransferFrom(opts, caller.MainnetAddr, to, tokenID, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return bal, nil
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC721MintableABI)
+}
+
+func (c *MainnetERC721MintableContract) BalanceOf(caller *client.Identity) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceOfContext(ctx, caller)
+}
+
+func (c *MainnetERC721MintableContract) BalanceOfContext(ctx context.Context, caller *client.Identity) (*big.Int, error) {
+	return c.BalanceContext(ctx, caller.MainnetAddr)
 }
 
 func (c *MainnetERC721MintableContract) OwnerOf(tokenID *big.Int) (common.Address, error) {
-	return c.contract.OwnerOf(nil, tokenID)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.OwnerOfContext(ctx, tokenID)
+}
+
+func (c *MainnetERC721MintableContract) OwnerOfContext(ctx context.Context, tokenID *big.Int) (common.Address, error) {
+	return c.contract.OwnerOf(callOpts(ctx), tokenID)
 }
 
-func ConnectToMainnetERC721MintableContract(ethClient *ethclient.Client, address string) (*MainnetERC721MintableContract, error) {
+func (c *MainnetERC721MintableContract) TokenAddress() common.Address {
+	return c.Address
+}
+
+func (c *MainnetERC721MintableContract) Balance(owner common.Address) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceContext(ctx, owner)
+}
+
+func (c *MainnetERC721MintableContract) BalanceContext(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return c.contract.BalanceOf(callOpts(ctx), owner)
+}
+
+func ConnectToMainnetERC721MintableContract(backend bind.ContractBackend, address string) (*MainnetERC721MintableContract, error) {
 	contractAddr := common.HexToAddress(address)
-	contract, err := ethcontract.NewSampleERC721MintableToken(contractAddr, ethClient)
+	contract, err := ethcontract.NewSampleERC721MintableToken(contractAddr, backend)
 	if err != nil {
 		return nil, err
 	}
 	return &MainnetERC721MintableContract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   contractAddr,
 	}, nil
 }
 
 func DeployMainnetERC721MintableContract(
-	ethClient *ethclient.Client, creator *client.Identity, gatewayAddr common.Address,
+	backend bind.ContractBackend, creator *client.Identity, gatewayAddr common.Address,
+) (*MainnetERC721MintableContract, error) {
+	ctx, cancel := txContext(DefaultTxTimeout)
+	defer cancel()
+	return DeployMainnetERC721MintableContractContext(ctx, backend, nil, creator, gatewayAddr)
+}
+
+// DeployMainnetERC721MintableContractContext sends the deploy tx using the given nonce manager,
+// which is also used by the returned wrapper, or leaves nonce assignment to the node if it's nil.
+func DeployMainnetERC721MintableContractContext(
+	ctx context.Context, backend bind.ContractBackend, nonces *NonceManager, creator *client.Identity,
+	gatewayAddr common.Address,
 ) (*MainnetERC721MintableContract, error) {
-	addr, tx, contract, err := ethcontract.DeploySampleERC721MintableToken(
-		client.DefaultTransactOptsForIdentity(creator),
-		ethClient,
-		gatewayAddr,
-	)
+	var addr common.Address
+	var contract *ethcontract.SampleERC721MintableToken
+	tx, err := sendTx(ctx, nonces, creator, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		deployedAddr, tx, deployed, err := ethcontract.DeploySampleERC721MintableToken(opts, backend, gatewayAddr)
+		addr, contract = deployedAddr, deployed
+		return tx, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := client.WaitForTxConfirmation(context.TODO(), ethClient, tx, 0); err != nil {
+	if _, err := WaitForTx(ctx, backend, tx); err != nil {
 		return nil, err
 	}
 	return &MainnetERC721MintableContract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   addr,
 		TxHash:    tx.Hash().Hex(),
+		Nonces:    nonces,
 	}, nil
 }
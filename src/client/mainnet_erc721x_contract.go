@@ -6,91 +6,174 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/loomnetwork/go-loom/client"
 )
 
+var mainnetERC721XABI = mustParseABI(ethcontract.MainnetERC721XCardsContractABI)
+
 type MainnetERC721XContract struct {
-	contract  *ethcontract.MainnetERC721XCardsContract
-	ethClient *ethclient.Client
+	contract *ethcontract.MainnetERC721XCardsContract
+	backend  bind.ContractBackend
 
 	TxTimeout time.Duration
 	Address   common.Address
 	TxHash    string
+	// Assigns the nonces of the txs sent through the contract, the node assigns them when nil
+	Nonces *NonceManager
 }
 
-func (c *MainnetERC721XContract) MintTokens(contractOwner *client.Identity, tokenID *big.Int, amount *big.Int, recipient *client.Identity) error {
-	tx, err := c.contract.MintTokens(
-		client.DefaultTransactOptsForIdentity(contractOwner),
-		recipient.MainnetAddr, tokenID, amount,
-	)
-	if err != nil {
-		return err
-	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+func (c *MainnetERC721XContract) MintTokens(contractOwner *client.Identity, tokenID *big.Int, amount *big.Int, recipient *client.Identity) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.MintTokensContext(ctx, contractOwner, tokenID, amount, recipient)
 }
 
-func (c *MainnetERC721XContract) DepositToGateway(caller *client.Identity, tokenID, amount *big.Int) error {
-	tx, err := c.contract.DepositToGateway(client.DefaultTransactOptsForIdentity(caller), tokenID, amount)
+func (c *MainnetERC721XContract) MintTokensContext(
+	ctx context.Context, contractOwner *client.Identity, tokenID *big.Int, amount *big.Int, recipient *client.Identity,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, contractOwner, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.MintTokens(opts, recipient.MainnetAddr, tokenID, amount)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return client.WaitForTxConfirmation(context.TODO(), c.ethClient, tx, c.TxTimeout)
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC721XABI)
 }
 
-func (c *MainnetERC721XContract) BalanceOf(caller *client.Identity, tokenID *big.Int) (*big.Int, error) {
-	bal, err := c.contract.BalanceOfToken(nil, caller.MainnetAddr, tokenID)
+func (c *MainnetERC721XContract) DepositToGateway(caller *client.Identity, tokenID, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.DepositToGatewayContext(ctx, caller, tokenID, amount)
+}
+
+func (c *MainnetERC721XContract) DepositToGatewayContext(
+	ctx context.Context, caller *client.Identity, tokenID, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.DepositToGateway(opts, tokenID, amount)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return bal, nil
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC721XABI)
+}
+
+func (c *MainnetERC721XContract) BalanceOf(caller *client.Identity, tokenID *big.Int) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceOfContext(ctx, caller, tokenID)
+}
+
+func (c *MainnetERC721XContract) BalanceOfContext(ctx context.Context, caller *client.Identity, tokenID *big.Int) (*big.Int, error) {
+	return c.TokenBalanceContext(ctx, caller.MainnetAddr, tokenID)
 }
 
 func (c *MainnetERC721XContract) TokenOfOwnerByIndex(caller *client.Identity, index int) (*big.Int, error) {
-	tokenID, err := c.contract.TokenOfOwnerByIndex(nil, caller.MainnetAddr, new(big.Int).SetInt64(int64(index)))
-	if err != nil {
-		return nil, err
-	}
-	return tokenID, nil
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TokenOfOwnerByIndexContext(ctx, caller, index)
+}
+
+func (c *MainnetERC721XContract) TokenOfOwnerByIndexContext(
+	ctx context.Context, caller *client.Identity, index int,
+) (*big.Int, error) {
+	return c.contract.TokenOfOwnerByIndex(callOpts(ctx), caller.MainnetAddr, new(big.Int).SetInt64(int64(index)))
 }
 
 func (c *MainnetERC721XContract) OwnerOf(tokenID *big.Int) (common.Address, error) {
-	return c.contract.OwnerOf(nil, tokenID)
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.OwnerOfContext(ctx, tokenID)
 }
 
-func ConnectToMainnetERC721XContract(ethClient *ethclient.Client, address string) (*MainnetERC721XContract, error) {
+func (c *MainnetERC721XContract) OwnerOfContext(ctx context.Context, tokenID *big.Int) (common.Address, error) {
+	return c.contract.OwnerOf(callOpts(ctx), tokenID)
+}
+
+func (c *MainnetERC721XContract) TokenAddress() common.Address {
+	return c.Address
+}
+
+func (c *MainnetERC721XContract) TokenBalance(owner common.Address, tokenID *big.Int) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TokenBalanceContext(ctx, owner, tokenID)
+}
+
+func (c *MainnetERC721XContract) TokenBalanceContext(ctx context.Context, owner common.Address, tokenID *big.Int) (*big.Int, error) {
+	return c.contract.BalanceOfToken(callOpts(ctx), owner, tokenID)
+}
+
+// SafeTransferTokens transfers tokens owned by the caller, the Gateway treats a transfer to it as
+// a deposit.
+func (c *MainnetERC721XContract) SafeTransferTokens(caller *client.Identity, to common.Address, tokenID, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.SafeTransferTokensContext(ctx, caller, to, tokenID, amount)
+}
+
+func (c *MainnetERC721XContract) SafeTransferTokensContext(
+	ctx context.Context, caller *client.Identity, to common.Address, tokenID, amount *big.Int,
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.SafeTransferFrom(opts, caller.MainnetAddr, to, tokenID, amount, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waitForTxResult(ctx, c.backend, tx, mainnetERC721XABI)
+}
+
+func ConnectToMainnetERC721XContract(backend bind.ContractBackend, address string) (*MainnetERC721XContract, error) {
 	contractAddr := common.HexToAddress(address)
-	contract, err := ethcontract.NewMainnetERC721XCardsContract(contractAddr, ethClient)
+	contract, err := ethcontract.NewMainnetERC721XCardsContract(contractAddr, backend)
 	if err != nil {
 		return nil, err
 	}
 	return &MainnetERC721XContract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   contractAddr,
 	}, nil
 }
 
 func DeployMainnetERC721XContract(
-	ethClient *ethclient.Client, creator *client.Identity, gatewayAddr common.Address,
+	backend bind.ContractBackend, creator *client.Identity, gatewayAddr common.Address,
+) (*MainnetERC721XContract, error) {
+	ctx, cancel := txContext(DefaultTxTimeout)
+	defer cancel()
+	return DeployMainnetERC721XContractContext(ctx, backend, nil, creator, gatewayAddr)
+}
+
+// DeployMainnetERC721XContractContext sends the deploy tx using the given nonce manager, which is
+// also used by the returned wrapper, or leaves nonce assignment to the node if it's nil.
+func DeployMainnetERC721XContractContext(
+	ctx context.Context, backend bind.ContractBackend, nonces *NonceManager, creator *client.Identity,
+	gatewayAddr common.Address,
 ) (*MainnetERC721XContract, error) {
-	addr, tx, contract, err := ethcontract.DeployMainnetERC721XCardsContract(
-		client.DefaultTransactOptsForIdentity(creator),
-		ethClient,
-		gatewayAddr,
-		"baseTokenURI",
-	)
+	var addr common.Address
+	var contract *ethcontract.MainnetERC721XCardsContract
+	tx, err := sendTx(ctx, nonces, creator, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		deployedAddr, tx, deployed, err := ethcontract.DeployMainnetERC721XCardsContract(opts, backend, gatewayAddr, "baseTokenURI")
+		addr, contract = deployedAddr, deployed
+		return tx, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := client.WaitForTxConfirmation(context.TODO(), ethClient, tx, 0); err != nil {
+	if _, err := WaitForTx(ctx, backend, tx); err != nil {
 		return nil, err
 	}
 	return &MainnetERC721XContract{
 		contract:  contract,
-		ethClient: ethClient,
+		backend:   backend,
+		TxTimeout: DefaultTxTimeout,
 		Address:   addr,
 		TxHash:    tx.Hash().Hex(),
+		Nonces:    nonces,
 	}, nil
 }
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/loomnetwork/go-loom/client"
+)
+
+// Number of times NonceManager.Transact resends a tx the node rejected because of its nonce.
+const maxNonceRetries = 3
+
+// TransactFunc sends a tx using the given transact options, e.g. by calling a method of a contract
+// binding generated by abigen.
+type TransactFunc func(opts *bind.TransactOpts) (*types.Transaction, error)
+
+// NonceManager assigns the nonces of the txs sent from each account locally, instead of leaving it
+// to the node, so that a single account can have many txs in flight at once. The same manager must
+// be used for all the txs sent from an account while it's in use.
+type NonceManager struct {
+	backend bind.ContractTransactor
+	mutex   sync.Mutex
+	// Next nonce of each account
+	nonces map[common.Address]uint64
+	// Last pending nonce fetched for each account, nonces below it were used by txs the node already
+	// has so they can't be handed out again.
+	pending map[common.Address]uint64
+	// Nonces of each account that were reserved but given up because their tx couldn't be sent,
+	// these are reused before any new nonces so that later txs aren't stuck behind the gap.
+	released map[common.Address][]uint64
+}
+
+// NewNonceManager creates a nonce manager that fetches pending nonces from the given backend.
+func NewNonceManager(backend bind.ContractTransactor) *NonceManager {
+	return &NonceManager{
+		backend:  backend,
+		nonces:   make(map[common.Address]uint64),
+		pending:  make(map[common.Address]uint64),
+		released: make(map[common.Address][]uint64),
+	}
+}
+
+// Next reserves the next nonce of the given account, the first nonce of an account is its pending
+// nonce. Released nonces are reserved first, lowest first.
+func (m *NonceManager) Next(ctx context.Context, account common.Address) (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if released := m.released[account]; len(released) > 0 {
+		m.released[account] = released[1:]
+		return released[0], nil
+	}
+	nonce, ok := m.nonces[account]
+	if !ok {
+		var err error
+		if nonce, err = m.backend.PendingNonceAt(ctx, account); err != nil {
+			return 0, err
+		}
+		m.pending[account] = nonce
+	}
+	m.nonces[account] = nonce + 1
+	return nonce, nil
+}
+
+// Release gives up a nonce reserved by Next that wasn't used, so that it's reserved again by the
+// next call to Next.
+func (m *NonceManager) Release(account common.Address, nonce uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	next, ok := m.nonces[account]
+	if !ok || nonce >= next || nonce < m.pending[account] {
+		// Never reserved, or used by another tx the node has seen since it was reserved
+		return
+	}
+	if nonce+1 == next {
+		m.nonces[account] = nonce
+		return
+	}
+	released := m.released[account]
+	i := sort.Search(len(released), func(i int) bool { return released[i] >= nonce })
+	if i < len(released) && released[i] == nonce {
+		return
+	}
+	released = append(released, 0)
+	copy(released[i+1:], released[i:])
+	released[i] = nonce
+	m.released[account] = released
+}
+
+// Resync moves the next nonce of the given account forward to the pending nonce of the account, in
+// case txs were sent from the account without the manager. Nonces that are reserved but not sent yet
+// are kept, so concurrent txs don't end up with the same nonce, while released nonces the node has
+// seen used since are dropped.
+func (m *NonceManager) Resync(ctx context.Context, account common.Address) error {
+	pending, err := m.backend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if next, ok := m.nonces[account]; !ok || next < pending {
+		m.nonces[account] = pending
+	}
+	if pending > m.pending[account] {
+		m.pending[account] = pending
+	}
+	released := m.released[account]
+	i := sort.Search(len(released), func(i int) bool { return released[i] >= m.pending[account] })
+	m.released[account] = released[i:]
+	return nil
+}
+
+// Transact calls send with the next nonce of the caller. If the tx can't be sent its nonce is
+// released, so the next tx sent from the caller fills the gap. If the node rejected the nonce
+// instead the nonces of the caller are resynced, and the tx is sent again with the next nonce.
+func (m *NonceManager) Transact(ctx context.Context, caller *client.Identity, send TransactFunc) (*types.Transaction, error) {
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.Next(ctx, caller.MainnetAddr)
+		if err != nil {
+			return nil, err
+		}
+		opts := transactOpts(ctx, caller)
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		tx, err := send(opts)
+		if err == nil {
+			return tx, nil
+		}
+		if !isNonceError(err) {
+			m.Release(caller.MainnetAddr, nonce)
+			return nil, err
+		}
+		if resyncErr := m.Resync(ctx, caller.MainnetAddr); resyncErr != nil {
+			return nil, resyncErr
+		}
+		if attempt >= maxNonceRetries {
+			return nil, err
+		}
+	}
+}
+
+// Returns true if the node rejected a tx because another tx from the same account already has, or
+// had, the same nonce.
+func isNonceError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "replacement transaction underpriced") ||
+		// Ganache
+		strings.Contains(msg, "doesn't have the correct nonce")
+}
+
+// Sends the tx using the given nonce manager, or leaves nonce assignment to the node if it's nil.
+func sendTx(ctx context.Context, nonces *NonceManager, caller *client.Identity, send TransactFunc) (*types.Transaction, error) {
+	if nonces == nil {
+		return send(transactOpts(ctx, caller))
+	}
+	return nonces.Transact(ctx, caller, send)
+}
+
+// TxGroup runs contract wrapper writes concurrently and waits for all of them together. When the
+// wrappers share a NonceManager this pipelines the txs sent from a single account, rather than
+// waiting for each tx to be mined before sending the next one.
+type TxGroup struct {
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	results []*TxResult
+	err     error
+}
+
+// Go runs the given write in a new goroutine.
+func (g *TxGroup) Go(write func() (*TxResult, error)) {
+	g.mutex.Lock()
+	i := len(g.results)
+	g.results = append(g.results, nil)
+	g.mutex.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		result, err := write()
+
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		g.results[i] = result
+		if err != nil && g.err == nil {
+			g.err = err
+		}
+	}()
+}
+
+// Wait waits for all the writes to complete, and returns their results in the order the writes were
+// started, along with the first error returned by any of them.
+func (g *TxGroup) Wait() ([]*TxResult, error) {
+	g.wg.Wait()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.results, g.err
+}
@@ -0,0 +1,263 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceManagerReusesReleasedNonces(t *testing.T) {
+	ctx := context.Background()
+	alice := newTestIdentity(t)
+	m := NewNonceManager(newTestBackend(alice))
+
+	for want := uint64(0); want < 3; want++ {
+		nonce, err := m.Next(ctx, alice.MainnetAddr)
+		require.NoError(t, err)
+		require.Equal(t, want, nonce)
+	}
+	m.Release(alice.MainnetAddr, 1)
+	m.Release(alice.MainnetAddr, 0)
+	// Already released, and never reserved
+	m.Release(alice.MainnetAddr, 1)
+	m.Release(alice.MainnetAddr, 5)
+	m.Release(newTestIdentity(t).MainnetAddr, 0)
+
+	for _, want := range []uint64{0, 1, 3} {
+		nonce, err := m.Next(ctx, alice.MainnetAddr)
+		require.NoError(t, err)
+		require.Equal(t, want, nonce)
+	}
+	// Releasing the last nonce reserved doesn't leave a gap
+	m.Release(alice.MainnetAddr, 3)
+	nonce, err := m.Next(ctx, alice.MainnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), nonce)
+}
+
+func TestNonceManagerFillsGapLeftByFailedTx(t *testing.T) {
+	ctx := context.Background()
+	alice := newTestIdentity(t)
+	bob := newTestIdentity(t)
+	backend := newTestBackend(alice)
+	token, err := DeployMainnetERC20Contract(backend, alice, common.Address{})
+	require.NoError(t, err)
+	m := NewNonceManager(backend)
+
+	transfer := func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return token.contract.Transfer(opts, bob.MainnetAddr, big.NewInt(1))
+	}
+	sendWithNonce := func(nonce uint64) {
+		opts := transactOpts(ctx, alice)
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		tx, err := transfer(opts)
+		require.NoError(t, err)
+		_, err = WaitForTx(ctx, backend, tx)
+		require.NoError(t, err)
+	}
+
+	// Reserved by a tx that's still being sent while the next tx fails, and by another tx that
+	// starts sending in the meantime.
+	first, err := m.Next(ctx, alice.MainnetAddr)
+	require.NoError(t, err)
+	var third uint64
+	failed, err := m.Transact(ctx, alice, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		require.Equal(t, first+1, opts.Nonce.Uint64())
+		var err error
+		third, err = m.Next(ctx, alice.MainnetAddr)
+		require.NoError(t, err)
+		return nil, errors.New("connection refused")
+	})
+	require.Error(t, err)
+	require.Nil(t, failed)
+	require.Equal(t, first+2, third)
+
+	sendWithNonce(first)
+	// The next tx takes the nonce of the failed one, otherwise the tx sent with the third nonce
+	// would never be mined.
+	tx, err := m.Transact(ctx, alice, transfer)
+	require.NoError(t, err)
+	require.Equal(t, first+1, tx.Nonce())
+	_, err = WaitForTx(ctx, backend, tx)
+	require.NoError(t, err)
+	sendWithNonce(third)
+
+	balance, err := token.BalanceContext(ctx, bob.MainnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, "3", balance.String())
+}
+
+func TestNonceManagerRetriesStaleNonce(t *testing.T) {
+	ctx := context.Background()
+	alice := newTestIdentity(t)
+	bob := newTestIdentity(t)
+	backend := newTestBackend(alice)
+	m := NewNonceManager(backend)
+	token, err := DeployMainnetERC20ContractContext(ctx, backend, m, alice, common.Address{})
+	require.NoError(t, err)
+	require.Equal(t, m, token.Nonces)
+
+	// The wrapper keeps using the manager the contract was deployed with
+	_, err = token.TransferToContext(ctx, alice, bob.MainnetAddr, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), m.nonces[alice.MainnetAddr])
+
+	// A tx sent without the manager makes the nonce it has for the account stale
+	tx, err := token.contract.Transfer(transactOpts(ctx, alice), bob.MainnetAddr, big.NewInt(1))
+	require.NoError(t, err)
+	_, err = WaitForTx(ctx, backend, tx)
+	require.NoError(t, err)
+
+	attempts := 0
+	tx, err = m.Transact(ctx, alice, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		attempts++
+		pending, err := backend.PendingNonceAt(ctx, alice.MainnetAddr)
+		require.NoError(t, err)
+		// The simulated chain panics on a nonce mismatch instead of rejecting the tx like a node
+		if opts.Nonce.Uint64() < pending {
+			return nil, errors.New("nonce too low")
+		}
+		return token.contract.Transfer(opts, bob.MainnetAddr, big.NewInt(1))
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, uint64(3), tx.Nonce())
+	_, err = WaitForTx(ctx, backend, tx)
+	require.NoError(t, err)
+}
+
+func TestNonceManagerTransactErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{name: "not a nonce error", err: errors.New("insufficient funds for gas * price + value"), wantAttempts: 1},
+		{name: "nonce error", err: errors.New("nonce too low"), wantAttempts: maxNonceRetries + 1},
+		{name: "ganache nonce error", err: errors.New("the tx doesn't have the correct nonce"), wantAttempts: maxNonceRetries + 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			alice := newTestIdentity(t)
+			m := NewNonceManager(newTestBackend(alice))
+
+			attempts := 0
+			_, err := m.Transact(ctx, alice, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				attempts++
+				return nil, test.err
+			})
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestNonceManagerReleasesNonceOfUnsentTx(t *testing.T) {
+	ctx := context.Background()
+	alice := newTestIdentity(t)
+	m := NewNonceManager(newTestBackend(alice))
+
+	_, err := m.Transact(ctx, alice, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		require.Equal(t, uint64(0), opts.Nonce.Uint64())
+		return nil, errors.New("connection refused")
+	})
+	require.Error(t, err)
+	nonce, err := m.Next(ctx, alice.MainnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), nonce)
+}
+
+// Stands in for a node that txs from a single account are sent to, it accepts each nonce once.
+type testNonceNode struct {
+	bind.ContractTransactor
+	mutex sync.Mutex
+	sent  map[uint64]bool
+}
+
+func (n *testNonceNode) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.pendingNonce(), nil
+}
+
+// Returns the first nonce that isn't used by any tx sent so far.
+func (n *testNonceNode) pendingNonce() uint64 {
+	nonce := uint64(0)
+	for n.sent[nonce] {
+		nonce++
+	}
+	return nonce
+}
+
+func (n *testNonceNode) send(opts *bind.TransactOpts) (*types.Transaction, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	nonce := opts.Nonce.Uint64()
+	if n.sent[nonce] {
+		if nonce < n.pendingNonce() {
+			return nil, errors.New("nonce too low")
+		}
+		return nil, errors.New("replacement transaction underpriced")
+	}
+	n.sent[nonce] = true
+	return types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil), nil
+}
+
+func TestNonceManagerConcurrentResync(t *testing.T) {
+	ctx := context.Background()
+	alice := newTestIdentity(t)
+	node := &testNonceNode{sent: make(map[uint64]bool)}
+	m := NewNonceManager(node)
+
+	// Reserved by a tx that doesn't get sent until the txs below are done
+	held, err := m.Next(ctx, alice.MainnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), held)
+	// Txs sent from the account without the manager, so the next nonce it has is stale
+	const sentElsewhere = 5
+	for nonce := uint64(0); nonce < sentElsewhere; nonce++ {
+		_, err := node.send(&bind.TransactOpts{Nonce: new(big.Int).SetUint64(nonce)})
+		require.NoError(t, err)
+	}
+
+	// Some of these get nonce errors and resync while the others hold nonces they haven't sent yet
+	const txCount = 20
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	txs := make([]*types.Transaction, txCount)
+	errs := make([]error, txCount)
+	for i := 0; i < txCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			txs[i], errs[i] = m.Transact(ctx, alice, node.send)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	used := make(map[uint64]bool)
+	for i := 0; i < txCount; i++ {
+		require.NoError(t, errs[i])
+		require.False(t, used[txs[i].Nonce()], "nonce %d used twice", txs[i].Nonce())
+		used[txs[i].Nonce()] = true
+	}
+	pending, err := node.PendingNonceAt(ctx, alice.MainnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(sentElsewhere+txCount), pending)
+
+	// The node has seen the held nonce used since, so it's not handed out again when released
+	m.Release(alice.MainnetAddr, held)
+	nonce, err := m.Next(ctx, alice.MainnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, pending, nonce)
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/loomnetwork/go-loom/client"
+)
+
+// Token is implemented by all the Ethereum token contract wrappers.
+type Token interface {
+	// TokenAddress returns the address of the token contract.
+	TokenAddress() common.Address
+}
+
+// FungibleToken is implemented by the ERC20 token contract wrappers.
+type FungibleToken interface {
+	Token
+	Balance(owner common.Address) (*big.Int, error)
+	Approve(owner *client.Identity, spender common.Address, amount *big.Int) (*TxResult, error)
+	TransferTo(owner *client.Identity, to common.Address, amount *big.Int) (*TxResult, error)
+}
+
+// NonFungibleToken is implemented by the ERC721 token contract wrappers.
+type NonFungibleToken interface {
+	Token
+	// Balance returns the number of tokens owned by the given account.
+	Balance(owner common.Address) (*big.Int, error)
+	OwnerOf(tokenID *big.Int) (common.Address, error)
+	// SafeTransferFrom transfers a token owned by the caller, the Gateway treats a transfer to it as
+	// a deposit.
+	SafeTransferFrom(owner *client.Identity, to common.Address, tokenID *big.Int) (*TxResult, error)
+}
+
+// MultiToken is implemented by the ERC721X token contract wrappers.
+type MultiToken interface {
+	Token
+	// TokenBalance returns the number of tokens with the given ID owned by the given account.
+	TokenBalance(owner common.Address, tokenID *big.Int) (*big.Int, error)
+	// SafeTransferTokens transfers tokens owned by the caller, the Gateway treats a transfer to it
+	// as a deposit.
+	SafeTransferTokens(owner *client.Identity, to common.Address, tokenID, amount *big.Int) (*TxResult, error)
+}
+
+var (
+	_ FungibleToken    = (*MainnetERC20Contract)(nil)
+	_ FungibleToken    = (*MainnetERC20MintableContract)(nil)
+	_ NonFungibleToken = (*MainnetERC721MintableContract)(nil)
+	_ NonFungibleToken = (*MainnetCryptoCardsClient)(nil)
+	_ MultiToken       = (*MainnetERC721XContract)(nil)
+	_ FungibleToken    = (*MainnetTokenContract)(nil)
+	_ NonFungibleToken = (*MainnetTokenContract)(nil)
+	_ MultiToken       = (*MainnetTokenContract)(nil)
+)
+
+// MainnetTokenContract is a token contract connected by address & ABI at runtime, the ABI must
+// declare the token methods with the same signatures as the sample token contracts, i.e.
+// balanceOf, approve, transfer, ownerOf, & safeTransferFrom for ERC20 & ERC721 tokens, and
+// balanceOfToken & safeTransferFrom for ERC721X tokens. Which of the token interfaces the contract
+// actually supports depends on the ABI.
+type MainnetTokenContract struct {
+	contract    *bind.BoundContract
+	contractABI abi.ABI
+	backend     bind.ContractBackend
+
+	TxTimeout time.Duration
+	Address   common.Address
+	// Assigns the nonces of the txs sent through the contract, the node assigns them when nil
+	Nonces *NonceManager
+}
+
+func (c *MainnetTokenContract) TokenAddress() common.Address {
+	return c.Address
+}
+
+func (c *MainnetTokenContract) Balance(owner common.Address) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.BalanceContext(ctx, owner)
+}
+
+func (c *MainnetTokenContract) BalanceContext(ctx context.Context, owner common.Address) (*big.Int, error) {
+	bal := new(*big.Int)
+	if err := c.contract.Call(callOpts(ctx), bal, "balanceOf", owner); err != nil {
+		return nil, err
+	}
+	return *bal, nil
+}
+
+func (c *MainnetTokenContract) TokenBalance(owner common.Address, tokenID *big.Int) (*big.Int, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TokenBalanceContext(ctx, owner, tokenID)
+}
+
+func (c *MainnetTokenContract) TokenBalanceContext(ctx context.Context, owner common.Address, tokenID *big.Int) (*big.Int, error) {
+	bal := new(*big.Int)
+	if err := c.contract.Call(callOpts(ctx), bal, "balanceOfToken", owner, tokenID); err != nil {
+		return nil, err
+	}
+	return *bal, nil
+}
+
+func (c *MainnetTokenContract) OwnerOf(tokenID *big.Int) (common.Address, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.OwnerOfContext(ctx, tokenID)
+}
+
+func (c *MainnetTokenContract) OwnerOfContext(ctx context.Context, tokenID *big.Int) (common.Address, error) {
+	owner := new(common.Address)
+	if err := c.contract.Call(callOpts(ctx), owner, "ownerOf", tokenID); err != nil {
+		return common.Address{}, err
+	}
+	return *owner, nil
+}
+
+func (c *MainnetTokenContract) Approve(owner *client.Identity, spender common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.ApproveContext(ctx, owner, spender, amount)
+}
+
+func (c *MainnetTokenContract) ApproveContext(
+	ctx context.Context, owner *client.Identity, spender common.Address, amount *big.Int,
+) (*TxResult, error) {
+	return c.transact(ctx, owner, "approve", spender, amount)
+}
+
+func (c *MainnetTokenContract) TransferTo(owner *client.Identity, to common.Address, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.TransferToContext(ctx, owner, to, amount)
+}
+
+func (c *MainnetTokenContract) TransferToContext(
+	ctx context.Context, owner *client.Identity, to common.Address, amount *big.Int,
+) (*TxResult, error) {
+	return c.transact(ctx, owner, "transfer", to, amount)
+}
+
+func (c *MainnetTokenContract) SafeTransferFrom(owner *client.Identity, to common.Address, tokenID *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.SafeTransferFromContext(ctx, owner, to, tokenID)
+}
+
+func (c *MainnetTokenContract) SafeTransferFromContext(
+	ctx context.Context, owner *client.Identity, to common.Address, tokenID *big.Int,
+) (*TxResult, error) {
+	return c.transact(ctx, owner, "safeTransferFrom", owner.MainnetAddr, to, tokenID, []byte{})
+}
+
+func (c *MainnetTokenContract) SafeTransferTokens(owner *client.Identity, to common.Address, tokenID, amount *big.Int) (*TxResult, error) {
+	ctx, cancel := txContext(c.TxTimeout)
+	defer cancel()
+	return c.SafeTransferTokensContext(ctx, owner, to, tokenID, amount)
+}
+
+func (c *MainnetTokenContract) SafeTransferTokensContext(
+	ctx context.Context, owner *client.Identity, to common.Address, tokenID, amount *big.Int,
+) (*TxResult, error) {
+	return c.transact(ctx, owner, "safeTransferFrom", owner.MainnetAddr, to, tokenID, amount, []byte{})
+}
+
+func (c *MainnetTokenContract) transact(
+	ctx context.Context, caller *client.Identity, method string, params ...interface{},
+) (*TxResult, error) {
+	tx, err := sendTx(ctx, c.Nonces, caller, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Transact(opts, method, params...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waitForTxResult(ctx, c.backend, tx, &c.contractABI)
+}
+
+// ConnectToMainnetToken connects to the token contract at the given address using the given ABI.
+func ConnectToMainnetToken(backend bind.ContractBackend, address string, contractABI abi.ABI) *MainnetTokenContract {
+	contractAddr := common.HexToAddress(address)
+	return &MainnetTokenContract{
+		contract:    bind.NewBoundContract(contractAddr, contractABI, backend, backend, backend),
+		contractABI: contractABI,
+		backend:     backend,
+		TxTimeout:   DefaultTxTimeout,
+		Address:     contractAddr,
+	}
+}
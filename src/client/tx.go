@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/loomnetwork/go-loom/client"
+)
+
+// DefaultTxTimeout is how long the methods that don't take a context wait for a tx to be mined,
+// unless the TxTimeout of the contract wrapper is set.
+const DefaultTxTimeout = 2 * time.Minute
+
+// How often WaitForTx checks if the tx has been mined.
+var txPollInterval = time.Second
+
+// Number of consecutive checks the node must fail to find the tx in before it's considered
+// dropped, since nodes behind a load balancer may not all see a new tx straight away.
+const txDroppedChecks = 3
+
+// TxTimeoutError is returned when the context expires or is cancelled before the tx is mined.
+type TxTimeoutError struct {
+	TxHash common.Hash
+	// The context error
+	Err error
+}
+
+func (e *TxTimeoutError) Error() string {
+	return fmt.Sprintf("tx %s wasn't mined in time: %v", e.TxHash.Hex(), e.Err)
+}
+
+// TxRevertedError is returned when the tx is mined but fails.
+type TxRevertedError struct {
+	TxHash  common.Hash
+	Receipt *types.Receipt
+}
+
+func (e *TxRevertedError) Error() string {
+	return fmt.Sprintf("tx %s reverted", e.TxHash.Hex())
+}
+
+// TxDroppedError is returned when the node no longer knows about a tx that hasn't been mined,
+// e.g. because it was evicted from the tx pool or replaced by another tx with the same nonce.
+type TxDroppedError struct {
+	TxHash common.Hash
+}
+
+func (e *TxDroppedError) Error() string {
+	return fmt.Sprintf("tx %s was dropped before it was mined", e.TxHash.Hex())
+}
+
+// WaitForTx waits for the given tx to be mined, and returns its receipt. Returns a
+// *TxTimeoutError if the context expires first, a *TxRevertedError if the tx fails, or a
+// *TxDroppedError if the tx disappears from the node before it's mined. Backends that implement
+// TxWaiter decide how to wait for their own txs, any other backend must implement TxReader.
+func WaitForTx(ctx context.Context, backend bind.ContractBackend, tx *types.Transaction) (*types.Receipt, error) {
+	if waiter, ok := backend.(TxWaiter); ok {
+		return waiter.WaitForTx(ctx, tx)
+	}
+	reader, ok := backend.(TxReader)
+	if !ok {
+		return nil, fmt.Errorf("backend %T can't look up txs", backend)
+	}
+	return pollForTx(ctx, reader, tx)
+}
+
+// Polls the backend until the tx is mined, or the node no longer knows about it.
+func pollForTx(ctx context.Context, reader TxReader, tx *types.Transaction) (*types.Receipt, error) {
+	txHash := tx.Hash()
+	notFound := 0
+	for {
+		receipt, err := reader.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return checkReceipt(txHash, receipt)
+		}
+		if ctx.Err() != nil {
+			return nil, &TxTimeoutError{TxHash: txHash, Err: ctx.Err()}
+		}
+		if err != ethereum.NotFound {
+			return nil, err
+		}
+
+		_, _, err = reader.TransactionByHash(ctx, txHash)
+		if err == ethereum.NotFound {
+			if notFound++; notFound >= txDroppedChecks {
+				return nil, &TxDroppedError{TxHash: txHash}
+			}
+		} else if err == nil {
+			notFound = 0
+		} else if ctx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &TxTimeoutError{TxHash: txHash, Err: ctx.Err()}
+		case <-time.After(txPollInterval):
+		}
+	}
+}
+
+func checkReceipt(txHash common.Hash, receipt *types.Receipt) (*types.Receipt, error) {
+	if receipt.Status == types.ReceiptStatusFailed {
+		return receipt, &TxRevertedError{TxHash: txHash, Receipt: receipt}
+	}
+	return receipt, nil
+}
+
+// Returns the context used by the methods that don't take one, it expires after the given
+// timeout, or after DefaultTxTimeout if the timeout is zero.
+func txContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		timeout = DefaultTxTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+func transactOpts(ctx context.Context, caller *client.Identity) *bind.TransactOpts {
+	opts := client.DefaultTransactOptsForIdentity(caller)
+	opts.Context = ctx
+	return opts
+}
+
+func callOpts(ctx context.Context) *bind.CallOpts {
+	return &bind.CallOpts{Context: ctx}
+}
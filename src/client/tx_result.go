@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxResult describes a mined tx sent by one of the contract wrappers.
+type TxResult struct {
+	TxHash      common.Hash
+	BlockNumber *big.Int
+	GasUsed     uint64
+	// Price paid for each unit of gas, which is the gas price of the tx since the wrappers only send
+	// legacy txs.
+	EffectiveGasPrice *big.Int
+	// Logs emitted by the tx, decoded using the ABI of the contract the tx was sent to.
+	Events  []*TxEvent
+	Receipt *types.Receipt
+}
+
+// Cost returns the amount of wei paid for the gas used by the tx.
+func (r *TxResult) Cost() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+}
+
+// Event returns the first event with the given name emitted by the tx, or nil if there isn't one.
+func (r *TxResult) Event(name string) *TxEvent {
+	for _, ev := range r.Events {
+		if ev.Name == name {
+			return ev
+		}
+	}
+	return nil
+}
+
+// TxEvent is a log emitted by a tx.
+type TxEvent struct {
+	// Name of the event, empty if the contract ABI doesn't declare an event matching the log, e.g.
+	// because the log was emitted by another contract.
+	Name string
+	// Event arguments by name, indexed arguments of dynamic types (strings, bytes, arrays) are only
+	// available as the hash stored in the log topic.
+	Args map[string]interface{}
+	Log  *types.Log
+}
+
+// Waits for the given tx to be mined and builds the result from its receipt. When the tx reverts the
+// result is returned along with the *TxRevertedError, so callers can still account for the gas spent.
+func waitForTxResult(
+	ctx context.Context, backend bind.ContractBackend, tx *types.Transaction, contractABI *abi.ABI,
+) (*TxResult, error) {
+	receipt, err := WaitForTx(ctx, backend, tx)
+	if receipt == nil {
+		return nil, err
+	}
+	return newTxResult(tx, receipt, contractABI), err
+}
+
+func newTxResult(tx *types.Transaction, receipt *types.Receipt, contractABI *abi.ABI) *TxResult {
+	events := make([]*TxEvent, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		events = append(events, decodeLog(contractABI, log))
+	}
+	return &TxResult{
+		TxHash:            tx.Hash(),
+		BlockNumber:       receipt.BlockNumber,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: tx.GasPrice(),
+		Events:            events,
+		Receipt:           receipt,
+	}
+}
+
+// Decodes the given log using the first event in the ABI whose ID matches the first log topic, logs
+// that can't be decoded are returned with just the raw log.
+func decodeLog(contractABI *abi.ABI, log *types.Log) *TxEvent {
+	ev := &TxEvent{Log: log}
+	if contractABI == nil || len(log.Topics) == 0 {
+		return ev
+	}
+	for _, event := range contractABI.Events {
+		if event.Anonymous || common.Hash(event.Id()) != log.Topics[0] {
+			continue
+		}
+		args, err := decodeEventArgs(event, log)
+		if err != nil {
+			return ev
+		}
+		ev.Name = event.Name
+		ev.Args = args
+		return ev
+	}
+	return ev
+}
+
+func decodeEventArgs(event abi.Event, log *types.Log) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(event.Inputs))
+	values, err := event.Inputs.NonIndexed().UnpackValues(log.Data)
+	if err != nil {
+		return nil, err
+	}
+	topics := log.Topics[1:]
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			if len(values) > 0 {
+				args[input.Name], values = values[0], values[1:]
+			}
+			continue
+		}
+		if len(topics) == 0 {
+			break
+		}
+		args[input.Name], topics = decodeTopic(input.Type, topics[0]), topics[1:]
+	}
+	return args, nil
+}
+
+func decodeTopic(t abi.Type, topic common.Hash) interface{} {
+	switch t.T {
+	case abi.AddressTy:
+		return common.BytesToAddress(topic.Bytes())
+	case abi.UintTy:
+		return topic.Big()
+	case abi.IntTy:
+		return math.S256(topic.Big())
+	case abi.BoolTy:
+		return topic[common.HashLength-1] == 1
+	}
+	return topic
+}
+
+// Parses one of the ABIs generated by abigen, these are known to be valid.
+func mustParseABI(contractABI string) *abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		panic(err)
+	}
+	return &parsed
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"gateway"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	bnbclient "github.com/binance-chain/go-sdk/client"
+	bnbtypes "github.com/binance-chain/go-sdk/common/types"
+	"github.com/binance-chain/go-sdk/keys"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// BEP2 token amounts are fixed point numbers with 8 decimals.
+const bep2Decimals = 8
+
+type issueTokenFlags struct {
+	PrivateKeyFile string
+	Account        string
+	Name           string
+	Symbol         string
+	Supply         string
+	Mintable       bool
+	Network        string
+	DexURI         string
+	SymbolKey      string
+}
+
+var issueTokenCmdFlags issueTokenFlags
+
+func newIssueTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bnb-issue-token",
+		Short: "Issue token on BNB network",
+		RunE:  bnbIssueToken,
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&issueTokenCmdFlags.PrivateKeyFile, "private-key", "",
+		"File containing the hex encoded private key of the token issuer")
+	flags.StringVar(&issueTokenCmdFlags.Account, "account", "token_owner",
+		"Test account whose mnemonic should be used to sign the tx, ignored if --private-key is set")
+	flags.StringVar(&issueTokenCmdFlags.Name, "name", "MOOL_Token", "Token name")
+	flags.StringVar(&issueTokenCmdFlags.Symbol, "symbol", "MOOL", "Token symbol")
+	flags.StringVar(&issueTokenCmdFlags.Supply, "supply", "1000000000",
+		"Total supply in whole tokens, e.g. 1000.5 (scaled by 10^8 before issuing)")
+	flags.BoolVar(&issueTokenCmdFlags.Mintable, "mintable", true, "Allow more tokens to be minted later")
+	flags.StringVar(&issueTokenCmdFlags.Network, "network", "testnet", "Binance Chain network: testnet or prod")
+	flags.StringVar(&issueTokenCmdFlags.DexURI, "dex", "",
+		"Binance DEX endpoint, defaults to testnet-dex.binance.org or dex.binance.org depending on --network")
+	flags.StringVar(&issueTokenCmdFlags.SymbolKey, "symbol-key", "",
+		"Deployment file key to record the issued token symbol under, e.g. binance_bep2_token_symbol")
+	return cmd
+}
+
+func bnbIssueToken(cmd *cobra.Command, args []string) error {
+	flags := issueTokenCmdFlags
+
+	supply, err := parseBEP2Amount(flags.Supply)
+	if err != nil {
+		return errors.Wrap(err, "invalid supply")
+	}
+
+	var network bnbtypes.ChainNetwork
+	var dexURI string
+	switch flags.Network {
+	case "testnet":
+		network = bnbtypes.TestNetwork
+		dexURI = "testnet-dex.binance.org"
+	case "prod":
+		network = bnbtypes.ProdNetwork
+		dexURI = "dex.binance.org"
+	default:
+		return errors.Errorf("invalid network %s, expected testnet or prod", flags.Network)
+	}
+	if flags.DexURI != "" {
+		dexURI = flags.DexURI
+	}
+
+	var keyManager keys.KeyManager
+	if flags.PrivateKeyFile != "" {
+		privKey, err := ioutil.ReadFile(flags.PrivateKeyFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read private key file")
+		}
+		keyManager, err = keys.NewPrivateKeyManager(strings.TrimSpace(string(privKey)))
+		if err != nil {
+			return errors.Wrap(err, "failed to load private key")
+		}
+	} else {
+		bnbKey, _ := gateway.GetBnbKeys(flags.Account)
+		keyManager, err = keys.NewMnemonicKeyManager(bnbKey)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load mnemonic for %s", flags.Account)
+		}
+	}
+
+	if cmdFlags.DryRun {
+		printPlanStep("issue %s (%s) with supply %s (%d) on %s (%s), mintable: %v, signed by %s",
+			flags.Name, flags.Symbol, flags.Supply, supply, dexURI, flags.Network, flags.Mintable,
+			keyManager.GetAddr().String(),
+		)
+		if flags.SymbolKey != "" {
+			printPlanStep("write issued token symbol to %s in %s", flags.SymbolKey, cmdFlags.EthereumDeploymentInfoPath)
+		}
+		return nil
+	}
+
+	client, err := bnbclient.NewDexClient(dexURI, network, keyManager)
+	if err != nil {
+		return err
+	}
+	issue, err := client.IssueToken(flags.Name, flags.Symbol, supply, true, flags.Mintable)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(textOut, "result: %+v\n", issue)
+	report.Tokens = append(report.Tokens, &tokenReport{
+		Name:   flags.Name,
+		Symbol: issue.Symbol,
+		Supply: supply,
+		TxHash: issue.Hash,
+	})
+
+	if flags.SymbolKey != "" {
+		deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to load deployment info file")
+		}
+		if err := deploymentInfo.Set(flags.SymbolKey, issue.Symbol); err != nil {
+			return err
+		}
+		if err := deploymentInfo.Save(); err != nil {
+			return errors.Wrap(err, "failed to update deployment info file")
+		}
+		fmt.Fprintln(textOut, "wrote to file...", deploymentInfo.Filename())
+		report.FilesWritten = append(report.FilesWritten, deploymentInfo.Filename())
+	}
+	return nil
+}
+
+// Converts a decimal amount of whole tokens to the number of base units of a BEP2 token.
+func parseBEP2Amount(amount string) (int64, error) {
+	parts := strings.SplitN(amount, ".", 2)
+	whole, frac := parts[0], ""
+	if len(parts) == 2 {
+		frac = parts[1]
+	}
+	if len(frac) > bep2Decimals {
+		return 0, errors.Errorf("%s has more than %d decimals", amount, bep2Decimals)
+	}
+	frac += strings.Repeat("0", bep2Decimals-len(frac))
+
+	n, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok || n.Sign() <= 0 {
+		return 0, errors.Errorf("%s is not a positive number", amount)
+	}
+	if !n.IsInt64() {
+		return 0, errors.Errorf("%s is too large", amount)
+	}
+	return n.Int64(), nil
+}
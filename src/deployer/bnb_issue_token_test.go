@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBEP2Amount(t *testing.T) {
+	tests := []struct {
+		amount  string
+		want    int64
+		wantErr bool
+	}{
+		{amount: "1", want: 100000000},
+		{amount: "1.5", want: 150000000},
+		{amount: "0.00000001", want: 1},
+		{amount: ".25", want: 25000000},
+		{amount: "2.", want: 200000000},
+		{amount: "007", want: 700000000},
+		{amount: "92233720368.54775807", want: 9223372036854775807},
+		// Amounts that don't fit in 8 decimals are rejected rather than rounded
+		{amount: "0.000000001", wantErr: true},
+		{amount: "1.123456789", wantErr: true},
+		{amount: "0.000000010", wantErr: true},
+		// Overflow
+		{amount: "92233720368.54775808", wantErr: true},
+		{amount: "100000000000", wantErr: true},
+		// Negative & zero
+		{amount: "-1", wantErr: true},
+		{amount: "-0.5", wantErr: true},
+		{amount: "0", wantErr: true},
+		{amount: "0.00000000", wantErr: true},
+		// Not a decimal number
+		{amount: "", wantErr: true},
+		{amount: ".", wantErr: true},
+		{amount: "1e8", wantErr: true},
+		{amount: "1.2.3", wantErr: true},
+		{amount: "0x10", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.amount, func(t *testing.T) {
+			got, err := parseBEP2Amount(test.amount)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
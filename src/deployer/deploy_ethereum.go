@@ -0,0 +1,196 @@
+package main
+
+import (
+	"client"
+	"fmt"
+	"gateway"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	loom_client "github.com/loomnetwork/go-loom/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newDeployEthereumCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "deploy-ethereum",
+		Short: "Deploys test contracts to Ethereum",
+		RunE:  deployEthereum,
+	}
+}
+
+func deployEthereum(cmd *cobra.Command, args []string) error {
+	ethereumContracts := map[string]bool{}
+	for _, contractName := range cmdFlags.EthereumContractNames {
+		ethereumContracts[contractName] = true
+	}
+
+	if len(ethereumContracts) == 0 {
+		return nil
+	}
+
+	loomCfg, err := gateway.ParseConfig([]string{cmdFlags.LoomDir})
+	if err != nil {
+		return errors.Wrap(err, "failed to parse loom config")
+	}
+
+	ethKey, dappchainKey := gateway.GetKeys("dan")
+	cardsCreator, err := loom_client.CreateIdentityStr(ethKey, dappchainKey, loomCfg.ChainID)
+	if err != nil {
+		return errors.Wrap(err, "failed to create cards creator identity")
+	}
+
+	ethKey, dappchainKey = gateway.GetKeys("trudy")
+	coinCreator, err := loom_client.CreateIdentityStr(ethKey, dappchainKey, loomCfg.ChainID)
+	if err != nil {
+		return errors.Wrap(err, "failed to create coin creator identity")
+	}
+
+	deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to load deployment info file")
+	}
+
+	if err := deploymentInfo.Require("mainnet_gateway_addr"); err != nil {
+		return err
+	}
+	if !common.IsHexAddress(deploymentInfo.MainnetGatewayAddr) {
+		return errors.New("Ethereum Gateway address in deployment info file is not an Ethereum address")
+	}
+	mainnetGatewayAddr := common.HexToAddress(deploymentInfo.MainnetGatewayAddr)
+
+	// Connected once the contract names have been checked
+	var ethClient *ethclient.Client
+
+	type ethereumContract struct {
+		name    string
+		creator string
+		signer  *loom_client.Identity
+		addrKey string
+		txKey   string
+		deploy  func() (common.Address, string, error)
+	}
+
+	contracts := []ethereumContract{
+		{
+			name: "CryptoCards", creator: "dan", signer: cardsCreator,
+			addrKey: "mainnet_crypto_cards_addr", txKey: "mainnet_crypto_cards_tx",
+			deploy: func() (common.Address, string, error) {
+				c, err := client.DeployMainnetCardsContract(ethClient, cardsCreator, mainnetGatewayAddr)
+				if err != nil {
+					return common.Address{}, "", err
+				}
+				return c.Address, c.TxHash, nil
+			},
+		},
+		{
+			name: "GameToken", creator: "trudy", signer: coinCreator,
+			addrKey: "mainnet_game_token_addr", txKey: "mainnet_game_token_tx",
+			deploy: func() (common.Address, string, error) {
+				c, err := client.DeployMainnetERC20Contract(ethClient, coinCreator, mainnetGatewayAddr)
+				if err != nil {
+					return common.Address{}, "", err
+				}
+				return c.Address, c.TxHash, nil
+			},
+		},
+		{
+			name: "ERC721XCards", creator: "dan", signer: cardsCreator,
+			addrKey: "mainnet_erc721x_cards_addr", txKey: "mainnet_erc721x_cards_tx",
+			deploy: func() (common.Address, string, error) {
+				c, err := client.DeployMainnetERC721XContract(ethClient, cardsCreator, mainnetGatewayAddr)
+				if err != nil {
+					return common.Address{}, "", err
+				}
+				return c.Address, c.TxHash, nil
+			},
+		},
+		{
+			name: "SampleERC20MintableToken", creator: "trudy", signer: coinCreator,
+			addrKey: "mainnet_erc20_mintable_token_addr", txKey: "mainnet_erc20_mintable_token_tx",
+			deploy: func() (common.Address, string, error) {
+				c, err := client.DeployMainnetERC20MintableContract(ethClient, coinCreator, mainnetGatewayAddr)
+				if err != nil {
+					return common.Address{}, "", err
+				}
+				return c.Address, c.TxHash, nil
+			},
+		},
+		{
+			name: "SampleERC721MintableToken", creator: "dan", signer: cardsCreator,
+			addrKey: "mainnet_erc721_mintable_token_addr", txKey: "mainnet_erc721_mintable_token_tx",
+			deploy: func() (common.Address, string, error) {
+				c, err := client.DeployMainnetERC721MintableContract(ethClient, cardsCreator, mainnetGatewayAddr)
+				if err != nil {
+					return common.Address{}, "", err
+				}
+				return c.Address, c.TxHash, nil
+			},
+		},
+	}
+
+	known := map[string]bool{}
+	for _, contract := range contracts {
+		known[contract.name] = true
+	}
+	for _, contractName := range cmdFlags.EthereumContractNames {
+		if !known[contractName] {
+			return errors.Errorf("unknown Ethereum contract %s", contractName)
+		}
+	}
+
+	if !cmdFlags.DryRun {
+		if ethClient, err = ethclient.Dial(loomCfg.TransferGateway.EthereumURI); err != nil {
+			return errors.Wrap(err, "failed to connect to Ethereum")
+		}
+		defer ethClient.Close()
+	}
+
+	saved := false
+	for _, contract := range contracts {
+		if !ethereumContracts[contract.name] {
+			continue
+		}
+
+		if cmdFlags.DryRun {
+			printPlanStep("deploy %s to Ethereum, signed by %s (%s), gateway %s, writes %s & %s to %s",
+				contract.name, contract.creator, contract.signer.MainnetAddr.Hex(),
+				mainnetGatewayAddr.Hex(), contract.addrKey, contract.txKey, cmdFlags.EthereumDeploymentInfoPath,
+			)
+			continue
+		}
+
+		start := time.Now()
+		addr, txHash, err := contract.deploy()
+		if err != nil {
+			return errors.Wrapf(err, "failed to deploy %s", contract.name)
+		}
+		fmt.Fprintf(textOut, "%s at %v\n", contract.name, addr.Hex())
+		report.Deployments = append(report.Deployments, &deploymentReport{
+			Name:       contract.name,
+			Chain:      "ethereum",
+			Address:    addr.Hex(),
+			TxHash:     txHash,
+			DurationMs: durationMs(start),
+		})
+		if err := deploymentInfo.Set(contract.addrKey, addr.Hex()); err != nil {
+			return err
+		}
+		if err := deploymentInfo.Set(contract.txKey, txHash); err != nil {
+			return err
+		}
+		// Persist the new addresses so map-contracts can pick them up, saving after every deployment
+		// so the contracts deployed before a failure are still recorded
+		if err := deploymentInfo.Save(); err != nil {
+			return errors.Wrap(err, "failed to update deployment info file")
+		}
+		if !saved {
+			fmt.Fprintln(textOut, "wrote to file...", deploymentInfo.Filename())
+			report.FilesWritten = append(report.FilesWritten, deploymentInfo.Filename())
+			saved = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"gateway"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
+	"github.com/spf13/cobra"
+)
+
+type depositFlags struct {
+	gatewayUserFlags
+	Kind           string
+	Amount         string
+	TokenID        string
+	Token          string
+	DAppChainToken string
+	HotWallet      bool
+	SafeTransfer   bool
+	TxTimeout      int
+	Timeout        int
+	PollInterval   int
+}
+
+var depositCmdFlags depositFlags
+
+type depositReport struct {
+	Owner         string   `json:"owner"`
+	Kind          string   `json:"kind"`
+	Gateway       string   `json:"gateway"`
+	MinedByBlock  uint64   `json:"mined_by_block,omitempty"`
+	BalanceBefore *big.Int `json:"balance_before,omitempty"`
+	BalanceAfter  *big.Int `json:"balance_after,omitempty"`
+}
+
+func newDepositCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit",
+		Short: "Deposits tokens from Ethereum to the DAppChain",
+		Long: "Sends the tokens to the Ethereum Gateway the way the token kind requires, waits for the " +
+			"number of block confirmations set in loom.yml, and then checks that the Oracle credited the " +
+			"tokens on the DAppChain. ERC20 deposits of the LOOM token go to the Loom Gateway.",
+		RunE: deposit,
+	}
+	flags := cmd.Flags()
+	depositCmdFlags.registerUserFlags(cmd)
+	flags.StringVar(&depositCmdFlags.Kind, "kind", "", "Kind of token to deposit: eth, loomcoin, erc20, erc721, or erc721x")
+	flags.StringVar(&depositCmdFlags.Amount, "amount", "",
+		"Amount to deposit in the smallest unit of the token, required for all kinds except erc721")
+	flags.StringVar(&depositCmdFlags.TokenID, "token-id", "", "ID of the token to deposit, required for erc721 & erc721x")
+	flags.StringVar(&depositCmdFlags.Token, "token", "",
+		"Address of the Ethereum token contract, or the key of the address in the deployment file, "+
+			"required for erc20, erc721 & erc721x")
+	flags.StringVar(&depositCmdFlags.DAppChainToken, "dappchain-token", "",
+		"Address of the DAppChain token contract the Ethereum token is mapped to, or the key of the address "+
+			"in the deployment file, required for erc20 (except the LOOM token), erc721 & erc721x")
+	flags.BoolVar(&depositCmdFlags.HotWallet, "hot-wallet", false,
+		"Transfer erc20 or loomcoin tokens straight to the Gateway and submit the tx hash to the DAppChain Gateway")
+	flags.BoolVar(&depositCmdFlags.SafeTransfer, "safe-transfer", false,
+		"Send erc721 tokens with safeTransferFrom even if the token contract implements depositToGateway")
+	flags.IntVar(&depositCmdFlags.TxTimeout, "tx-timeout", 120, "Max number of seconds to wait for each tx to be mined")
+	flags.IntVar(&depositCmdFlags.Timeout, "timeout", 300,
+		"Max number of seconds to wait for the block confirmations, and then for the Oracle to credit the deposit")
+	flags.IntVar(&depositCmdFlags.PollInterval, "poll-interval", 5,
+		"Number of seconds to wait between checks of the DAppChain balance")
+	cmd.MarkFlagRequired("kind")
+	return cmd
+}
+
+func deposit(cmd *cobra.Command, args []string) error {
+	flags := depositCmdFlags
+	kind, err := gateway.ParseTokenKind(flags.Kind)
+	if err != nil {
+		return err
+	}
+	dep := &gateway.Deposit{Kind: kind, HotWallet: flags.HotWallet, SafeTransfer: flags.SafeTransfer}
+	if dep.Amount, err = parseBigIntFlag("amount", flags.Amount); err != nil {
+		return err
+	}
+	if dep.TokenID, err = parseBigIntFlag("token-id", flags.TokenID); err != nil {
+		return err
+	}
+
+	session, err := connectAsGatewayUser(&flags.gatewayUserFlags, kind)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	if flags.Token != "" {
+		tokenAddr, err := session.resolveAddress(flags.Token)
+		if err != nil {
+			return err
+		}
+		dep.TokenContract = common.HexToAddress(tokenAddr)
+	}
+	// ERC20 deposits of the LOOM token don't need a DAppChain token, the Depositor checks that it's
+	// set once it knows which token is being deposited.
+	isERC20WithoutToken := kind == tgtypes.TransferGatewayTokenKind_ERC20 && flags.DAppChainToken == ""
+	if kind != tgtypes.TransferGatewayTokenKind_ETH && kind != tgtypes.TransferGatewayTokenKind_LOOMCOIN &&
+		!isERC20WithoutToken {
+		if dep.DAppChainToken, err = session.dappchainToken(flags.DAppChainToken); err != nil {
+			return err
+		}
+	}
+
+	depositor := &gateway.Depositor{
+		LoomClient:    session.loomClient,
+		EthClient:     session.ethClient,
+		Confirmations: session.loomCfg.TransferGateway.NumMainnetBlockConfirmations,
+		TxTimeout:     time.Duration(flags.TxTimeout) * time.Second,
+		PollInterval:  time.Duration(flags.PollInterval) * time.Second,
+		Timeout:       time.Duration(flags.Timeout) * time.Second,
+	}
+	// The main Ethereum Gateway is always needed to figure out which Gateway an ERC20 deposit goes
+	// to, the LOOM Gateways are only needed for LOOM deposits.
+	if kind == tgtypes.TransferGatewayTokenKind_LOOMCOIN {
+		depositor.DAppChainLoomGateway = session.dappchainGateway
+		depositor.MainnetLoomGateway = session.mainnetGateway
+		if depositor.DAppChainGateway, depositor.MainnetGateway, err = session.connectGateways(false); err != nil {
+			return err
+		}
+	} else {
+		depositor.DAppChainGateway = session.dappchainGateway
+		depositor.MainnetGateway = session.mainnetGateway
+		if kind == tgtypes.TransferGatewayTokenKind_ERC20 && session.deploymentInfo.MainnetLoomGatewayAddr != "" {
+			if depositor.DAppChainLoomGateway, depositor.MainnetLoomGateway, err = session.connectGateways(true); err != nil {
+				return err
+			}
+		}
+	}
+
+	owner := session.identity.MainnetAddr.Hex()
+	r := &depositReport{Owner: owner, Kind: gateway.TokenKindName(kind)}
+	report.Deposit = r
+	if cmdFlags.DryRun {
+		return printDepositPlan(dep, depositor, session.identity.MainnetAddr)
+	}
+
+	result, err := depositor.Deposit(session.identity, dep)
+	if result != nil {
+		r.Kind = gateway.TokenKindName(result.Kind)
+		r.Gateway = result.Gateway.Hex()
+		r.MinedByBlock = result.MinedByBlock
+		r.BalanceBefore = result.BalanceBefore
+		r.BalanceAfter = result.BalanceAfter
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(textOut, "deposited %s to Ethereum Gateway %s by block %d, DAppChain balance: %v -> %v\n",
+		r.Kind, r.Gateway, r.MinedByBlock, r.BalanceBefore, r.BalanceAfter)
+	return nil
+}
+
+func printDepositPlan(dep *gateway.Deposit, depositor *gateway.Depositor, owner common.Address) error {
+	// ERC20 deposits of the LOOM token go to the Loom Gateway, just like when the deposit is sent
+	routedKind, _, mainnetGateway, err := depositor.Route(dep)
+	if err != nil {
+		return err
+	}
+	kind := gateway.TokenKindName(routedKind)
+	gatewayAddr := mainnetGateway.Address.Hex()
+	switch routedKind {
+	case tgtypes.TransferGatewayTokenKind_ETH:
+		printPlanStep("deposit %v wei from %s to Ethereum Gateway %s", dep.Amount, owner.Hex(), gatewayAddr)
+	case tgtypes.TransferGatewayTokenKind_ERC20, tgtypes.TransferGatewayTokenKind_LOOMCOIN:
+		if dep.HotWallet {
+			printPlanStep("transfer %v %s tokens %s from %s to Ethereum Gateway %s, and submit the tx hash to the DAppChain Gateway",
+				dep.Amount, kind, dep.TokenContract.Hex(), owner.Hex(), gatewayAddr)
+		} else {
+			printPlanStep("approve & deposit %v %s tokens %s from %s to Ethereum Gateway %s",
+				dep.Amount, kind, dep.TokenContract.Hex(), owner.Hex(), gatewayAddr)
+		}
+	case tgtypes.TransferGatewayTokenKind_ERC721:
+		safeTransfer, err := depositor.UsesSafeTransfer(dep)
+		if err != nil {
+			return err
+		}
+		method := "depositToGateway"
+		if safeTransfer {
+			method = "safeTransferFrom"
+		}
+		printPlanStep("deposit ERC721 token %v of %s from %s to Ethereum Gateway %s via %s",
+			dep.TokenID, dep.TokenContract.Hex(), owner.Hex(), gatewayAddr, method)
+	case tgtypes.TransferGatewayTokenKind_ERC721X:
+		printPlanStep("deposit %v ERC721X tokens %v of %s from %s to Ethereum Gateway %s",
+			dep.Amount, dep.TokenID, dep.TokenContract.Hex(), owner.Hex(), gatewayAddr)
+	}
+	printPlanStep("wait for %d block confirmations, and for the Oracle to credit the deposit on the DAppChain",
+		depositor.Confirmations)
+	return nil
+}
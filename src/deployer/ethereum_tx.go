@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"gateway"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	loom_client "github.com/loomnetwork/go-loom/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Flags shared by the commands that send txs to Ethereum.
+type ethereumSenderFlags struct {
+	SenderKeyFile  string
+	SenderAccount  string
+	ConfirmTimeout int
+}
+
+func (f *ethereumSenderFlags) registerSenderFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.SenderKeyFile, "private-key", "",
+		"File containing the hex encoded private key of the account that should submit the tx")
+	flags.StringVar(&f.SenderAccount, "account", "alice",
+		"Test account that should submit the tx, ignored if --private-key is set")
+	flags.IntVar(&f.ConfirmTimeout, "timeout", 120, "Max number of seconds to wait for the tx to be mined")
+}
+
+// Loads the key of the account that should submit the tx.
+func (f *ethereumSenderFlags) senderKey() (*ecdsa.PrivateKey, error) {
+	if f.SenderKeyFile != "" {
+		return loadEthereumKeyFile(f.SenderKeyFile)
+	}
+	key, err := parseEthereumKey(gateway.GetTestAccountKey(f.SenderAccount + "_eth"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load Ethereum key for %s", f.SenderAccount)
+	}
+	return key, nil
+}
+
+func loadEthereumKeyFile(filename string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read private key file")
+	}
+	key, err := parseEthereumKey(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load private key from %s", filename)
+	}
+	return key, nil
+}
+
+func parseEthereumKey(hexKey string) (*ecdsa.PrivateKey, error) {
+	return crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+}
+
+// Connects to the Ethereum network specified in the loom config.
+func dialEthereum() (*ethclient.Client, error) {
+	loomCfg, err := gateway.ParseConfig([]string{cmdFlags.LoomDir})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse loom config")
+	}
+	ethClient, err := ethclient.Dial(loomCfg.TransferGateway.EthereumURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to Ethereum")
+	}
+	return ethClient, nil
+}
+
+// Sends a tx signed by the given key, waits for it to be mined, and records it in the report.
+func sendEthereumTx(
+	ethClient *ethclient.Client, name string, key *ecdsa.PrivateKey, timeoutSecs int,
+	send func(opts *bind.TransactOpts) (*types.Transaction, error),
+) error {
+	start := time.Now()
+	tx, err := send(bind.NewKeyedTransactor(key))
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %s", name)
+	}
+	fmt.Fprintf(textOut, "sent %s tx %s\n", name, tx.Hash().Hex())
+	timeout := time.Duration(timeoutSecs) * time.Second
+	if err := loom_client.WaitForTxConfirmation(context.TODO(), ethClient, tx, timeout); err != nil {
+		return errors.Wrapf(err, "failed to confirm tx %s", tx.Hash().Hex())
+	}
+	report.Transactions = append(report.Transactions, &txReport{
+		Name:       name,
+		TxHash:     tx.Hash().Hex(),
+		DurationMs: durationMs(start),
+	})
+	return nil
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"ethcontract"
+	"fmt"
+	"gateway"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Flags shared by the gateway-admin commands.
+type gatewayAdminFlags struct {
+	GatewayAddress string
+	ethereumSenderFlags
+}
+
+func (f *gatewayAdminFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.GatewayAddress, "gateway", "",
+		"Address of the Ethereum Gateway, defaults to mainnet_gateway_addr in the deployment file")
+	f.registerSenderFlags(cmd)
+}
+
+func (f *gatewayAdminFlags) gatewayAddress() (common.Address, error) {
+	return resolveEthereumGatewayAddress(f.GatewayAddress)
+}
+
+// Returns the address of the Ethereum Gateway specified via --gateway, or the one listed in the
+// deployment file if the flag wasn't set.
+func resolveEthereumGatewayAddress(addr string) (common.Address, error) {
+	if addr == "" {
+		deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+		if err != nil {
+			return common.Address{}, errors.Wrap(err, "failed to load deployment info file")
+		}
+		if err := deploymentInfo.Require("mainnet_gateway_addr"); err != nil {
+			return common.Address{}, err
+		}
+		addr = deploymentInfo.MainnetGatewayAddr
+	}
+	if !common.IsHexAddress(addr) {
+		return common.Address{}, errors.Errorf("invalid Ethereum Gateway address %s", addr)
+	}
+	return common.HexToAddress(addr), nil
+}
+
+// gatewayAdminSession holds everything the gateway-admin commands need to send txs to the
+// Ethereum Gateway.
+type gatewayAdminSession struct {
+	flags     *gatewayAdminFlags
+	ethClient *ethclient.Client
+	gateway   *ethcontract.MainnetGatewayContract
+	address   common.Address
+	key       *ecdsa.PrivateKey
+}
+
+// Connects to the Ethereum Gateway, and checks that the account that will send the txs is the
+// owner of the Gateway, since the contract rejects admin txs from anyone else.
+func connectAsGatewayOwner(flags *gatewayAdminFlags) (*gatewayAdminSession, error) {
+	key, err := flags.senderKey()
+	if err != nil {
+		return nil, err
+	}
+	gatewayAddr, err := flags.gatewayAddress()
+	if err != nil {
+		return nil, err
+	}
+	ethClient, err := dialEthereum()
+	if err != nil {
+		return nil, err
+	}
+	gw, err := ethcontract.NewMainnetGatewayContract(gatewayAddr, ethClient)
+	if err != nil {
+		ethClient.Close()
+		return nil, errors.Wrap(err, "failed to connect to Ethereum Gateway")
+	}
+
+	owner, err := gw.GetOwner(nil)
+	if err != nil {
+		ethClient.Close()
+		return nil, errors.Wrap(err, "failed to fetch Ethereum Gateway owner")
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	if owner != sender {
+		ethClient.Close()
+		return nil, errors.Errorf("%s is not the owner of the Ethereum Gateway %s, the owner is %s",
+			sender.Hex(), gatewayAddr.Hex(), owner.Hex())
+	}
+
+	return &gatewayAdminSession{
+		flags:     flags,
+		ethClient: ethClient,
+		gateway:   gw,
+		address:   gatewayAddr,
+		key:       key,
+	}, nil
+}
+
+func (s *gatewayAdminSession) Close() {
+	s.ethClient.Close()
+}
+
+func (s *gatewayAdminSession) sendTx(name string, send func(opts *bind.TransactOpts) (*types.Transaction, error)) error {
+	return sendEthereumTx(s.ethClient, name, s.key, s.flags.ConfirmTimeout, send)
+}
+
+func (s *gatewayAdminSession) printState(label string) error {
+	enabled, err := s.gateway.GetGatewayEnabled(nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if Ethereum Gateway is enabled")
+	}
+	allowAnyToken, err := s.gateway.GetAllowAnyToken(nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if Ethereum Gateway allows any token")
+	}
+	fmt.Fprintf(textOut, "%s: Ethereum Gateway %s enabled: %v, allow any token: %v\n",
+		label, s.address.Hex(), enabled, allowAnyToken)
+	return nil
+}
+
+func newGatewayAdminCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gateway-admin",
+		Short: "Administers the Ethereum Gateway, must be run by the Gateway owner",
+	}
+	cmd.AddCommand(
+		newGatewayEnableCmd("enable", "Resumes deposits & withdrawals via the Ethereum Gateway", true),
+		newGatewayEnableCmd("disable", "Pauses deposits & withdrawals via the Ethereum Gateway", false),
+		newGatewayAllowAnyTokenCmd(),
+		newGatewaySyncAllowlistCmd(),
+	)
+	return cmd
+}
+
+func newGatewayEnableCmd(use, short string, enable bool) *cobra.Command {
+	var flags gatewayAdminFlags
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := connectAsGatewayOwner(&flags)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			if err := s.printState("before"); err != nil {
+				return err
+			}
+			if cmdFlags.DryRun {
+				printPlanStep("%s Ethereum Gateway %s", use, s.address.Hex())
+				return nil
+			}
+			err = s.sendTx("enableGateway", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return s.gateway.EnableGateway(opts, enable)
+			})
+			if err != nil {
+				return err
+			}
+			return s.printState("after")
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func newGatewayAllowAnyTokenCmd() *cobra.Command {
+	var flags gatewayAdminFlags
+	cmd := &cobra.Command{
+		Use:   "allow-any-token <true|false>",
+		Short: "Allows deposits of any token via the Ethereum Gateway, or only the allowlisted ones",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			allow, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return errors.Errorf("expected true or false, got %s", args[0])
+			}
+			s, err := connectAsGatewayOwner(&flags)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			if err := s.printState("before"); err != nil {
+				return err
+			}
+			if cmdFlags.DryRun {
+				printPlanStep("set allow any token to %v on Ethereum Gateway %s", allow, s.address.Hex())
+				return nil
+			}
+			err = s.sendTx("toggleAllowAnyToken", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return s.gateway.ToggleAllowAnyToken(opts, allow)
+			})
+			if err != nil {
+				return err
+			}
+			return s.printState("after")
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// allowlistToken is an entry in the token allowlist file.
+type allowlistToken struct {
+	Name string `mapstructure:"name"`
+	// Literal token address
+	Address string `mapstructure:"address"`
+	// Deployment file key of the token address (alternative to address)
+	AddressKey string `mapstructure:"address_key"`
+}
+
+type tokenAllowlist struct {
+	Tokens []allowlistToken `mapstructure:"tokens"`
+}
+
+type syncAllowlistFlags struct {
+	gatewayAdminFlags
+	AllowlistPath string
+	FromBlock     uint64
+	BlockRange    uint64
+}
+
+var syncAllowlistCmdFlags syncAllowlistFlags
+
+func newGatewaySyncAllowlistCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync-allowlist",
+		Short: "Updates the tokens allowed by the Ethereum Gateway to match the allowlist file",
+		RunE:  syncAllowlist,
+	}
+	syncAllowlistCmdFlags.register(cmd)
+	cmd.Flags().StringVar(&syncAllowlistCmdFlags.AllowlistPath, "file", "",
+		"YAML or JSON file listing the allowed tokens, e.g. e2e_config/token_allowlist.yml")
+	// Tokens missing from the file are disallowed, so it must never default to some other file
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().Uint64Var(&syncAllowlistCmdFlags.FromBlock, "from-block", 0,
+		"Block to start scanning for previously deposited tokens from")
+	cmd.Flags().Uint64Var(&syncAllowlistCmdFlags.BlockRange, "block-range", 10000,
+		"Max number of blocks to fetch events for in one request, 0 to fetch all events in one request")
+	return cmd
+}
+
+// Loads the YAML or JSON allowlist file, and resolves the token addresses.
+func loadTokenAllowlist(filename string) (map[common.Address]string, error) {
+	v := viper.New()
+	v.SetConfigFile(filename)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read token allowlist %s", filename)
+	}
+	var allowlist tokenAllowlist
+	if err := v.Unmarshal(&allowlist); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse token allowlist %s", filename)
+	}
+
+	var deploymentInfo *gateway.DeploymentInfo
+	tokens := map[common.Address]string{}
+	for i, token := range allowlist.Tokens {
+		addr := token.Address
+		if token.AddressKey != "" {
+			if deploymentInfo == nil {
+				var err error
+				deploymentInfo, err = gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to load deployment info file")
+				}
+			}
+			addr = deploymentInfo.Get(token.AddressKey)
+		}
+		if !common.IsHexAddress(addr) {
+			return nil, errors.Errorf("invalid address for token #%d (%s) in %s", i, token.Name, filename)
+		}
+		tokens[common.HexToAddress(addr)] = token.Name
+	}
+	return tokens, nil
+}
+
+func syncAllowlist(cmd *cobra.Command, args []string) error {
+	flags := syncAllowlistCmdFlags
+	allowlist, err := loadTokenAllowlist(flags.AllowlistPath)
+	if err != nil {
+		return err
+	}
+
+	s, err := connectAsGatewayOwner(&flags.gatewayAdminFlags)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if err := s.printState("current"); err != nil {
+		return err
+	}
+
+	// The allowlist is a mapping, so the tokens that are currently allowed can't be listed.
+	// Tokens that have been deposited are the only ones that can be allowed without being in the
+	// allowlist file, so those are checked in addition to the ones in the file.
+	tokens := map[common.Address]string{}
+	for addr, name := range allowlist {
+		tokens[addr] = name
+	}
+	deposited, err := s.depositedTokens(flags.FromBlock, flags.BlockRange)
+	if err != nil {
+		return err
+	}
+	for _, addr := range deposited {
+		if _, ok := tokens[addr]; !ok {
+			tokens[addr] = ""
+		}
+	}
+
+	addrs := make([]common.Address, 0, len(tokens))
+	for addr := range tokens {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Hex() < addrs[j].Hex()
+	})
+
+	type allowlistChange struct {
+		addr  common.Address
+		name  string
+		allow bool
+	}
+	var changes []allowlistChange
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOKEN\tADDRESS\tALLOWED\tIN FILE\tCHANGE")
+	for _, addr := range addrs {
+		name := tokens[addr]
+		allowed, err := s.gateway.AllowedTokens(nil, addr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check if %s is allowed", addr.Hex())
+		}
+		_, allow := allowlist[addr]
+		change := "-"
+		if allowed != allow {
+			if allow {
+				change = "allow"
+			} else {
+				change = "disallow"
+			}
+			changes = append(changes, allowlistChange{addr: addr, name: name, allow: allow})
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%v\t%s\n", name, addr.Hex(), allowed, allow, change)
+	}
+	w.Flush()
+
+	if len(changes) == 0 {
+		fmt.Fprintln(textOut, "allowlist is up to date")
+		return nil
+	}
+
+	for _, change := range changes {
+		if cmdFlags.DryRun {
+			printPlanStep("set allowed to %v for %s (%s) on Ethereum Gateway %s",
+				change.allow, change.name, change.addr.Hex(), s.address.Hex())
+			continue
+		}
+		change := change
+		err := s.sendTx("toggleAllowToken", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return s.gateway.ToggleAllowToken(opts, change.addr, change.allow)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update allowlist entry for %s", change.addr.Hex())
+		}
+	}
+	return nil
+}
+
+// Returns the addresses of the ERC20, ERC721, and ERC721X tokens deposited to the Gateway since
+// the given block, the events are fetched in ranges of at most blockRange blocks.
+func (s *gatewayAdminSession) depositedTokens(fromBlock, blockRange uint64) ([]common.Address, error) {
+	toBlock, err := latestBlockNumber(s.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	if fromBlock > toBlock {
+		return nil, nil
+	}
+	var tokens []common.Address
+	err = scanBlockRanges(fromBlock, toBlock, blockRange, func(opts *bind.FilterOpts) error {
+		erc20Deposits, err := s.gateway.FilterERC20Received(opts)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch ERC20 deposits")
+		}
+		defer erc20Deposits.Close()
+		for erc20Deposits.Next() {
+			tokens = append(tokens, erc20Deposits.Event.ContractAddress)
+		}
+		if err := erc20Deposits.Error(); err != nil {
+			return errors.Wrap(err, "failed to fetch ERC20 deposits")
+		}
+
+		erc721Deposits, err := s.gateway.FilterERC721Received(opts)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch ERC721 deposits")
+		}
+		defer erc721Deposits.Close()
+		for erc721Deposits.Next() {
+			tokens = append(tokens, erc721Deposits.Event.ContractAddress)
+		}
+		if err := erc721Deposits.Error(); err != nil {
+			return errors.Wrap(err, "failed to fetch ERC721 deposits")
+		}
+
+		erc721xDeposits, err := s.gateway.FilterERC721XReceived(opts)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch ERC721X deposits")
+		}
+		defer erc721xDeposits.Close()
+		for erc721xDeposits.Next() {
+			tokens = append(tokens, erc721xDeposits.Event.ContractAddress)
+		}
+		if err := erc721xDeposits.Error(); err != nil {
+			return errors.Wrap(err, "failed to fetch ERC721X deposits")
+		}
+
+		erc721xBatchDeposits, err := s.gateway.FilterERC721XBatchReceived(opts)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch ERC721X batch deposits")
+		}
+		defer erc721xBatchDeposits.Close()
+		for erc721xBatchDeposits.Next() {
+			tokens = append(tokens, erc721xBatchDeposits.Event.ContractAddress)
+		}
+		if err := erc721xBatchDeposits.Error(); err != nil {
+			return errors.Wrap(err, "failed to fetch ERC721X batch deposits")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Calls scan for consecutive ranges of at most blockRange blocks from fromBlock to toBlock, or once
+// for all the blocks if blockRange is zero, since nodes limit the number of events they return.
+func scanBlockRanges(fromBlock, toBlock, blockRange uint64, scan func(opts *bind.FilterOpts) error) error {
+	for start := fromBlock; start <= toBlock; {
+		end := toBlock
+		if blockRange > 0 && end-start >= blockRange {
+			end = start + blockRange - 1
+		}
+		fmt.Fprintf(textOut, "scanning blocks %d-%d\n", start, end)
+		if err := scan(&bind.FilterOpts{Start: start, End: &end}); err != nil {
+			return errors.Wrapf(err, "failed to scan blocks %d-%d", start, end)
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+func latestBlockNumber(ethClient *ethclient.Client) (uint64, error) {
+	header, err := ethClient.HeaderByNumber(context.TODO(), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to fetch latest Ethereum block")
+	}
+	return header.Number.Uint64(), nil
+}
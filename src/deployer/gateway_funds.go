@@ -0,0 +1,723 @@
+package main
+
+import (
+	"client"
+	"encoding/json"
+	"ethcontract"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	loom_client "github.com/loomnetwork/go-loom/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of funds held by the Ethereum Gateway.
+const (
+	fundKindETH     = "ETH"
+	fundKindERC20   = "ERC20"
+	fundKindERC721  = "ERC721"
+	fundKindERC721X = "ERC721X"
+)
+
+// ERC165 interface ID of ERC721.
+var erc721InterfaceID = [4]byte{0x80, 0xac, 0x58, 0xcd}
+
+// contractMapping is an entry in a contract mapping file, the Local address is the DAppChain
+// contract, and the Foreign address is the Ethereum contract it's mapped to.
+type contractMapping struct {
+	Local   string `json:"local"`
+	Foreign string `json:"foreign"`
+}
+
+// Loads a contract mapping file in the format used by transfer_gateway_funds.js, the mappings can
+// be listed under either "confirmed" or "data".
+func loadContractMappings(filename string) ([]contractMapping, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read contract mapping file")
+	}
+	var mappings struct {
+		Confirmed []contractMapping `json:"confirmed"`
+		Data      []contractMapping `json:"data"`
+	}
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	all := append(mappings.Confirmed, mappings.Data...)
+	for i, mapping := range all {
+		if !common.IsHexAddress(mapping.Foreign) {
+			return nil, errors.Errorf("invalid foreign address for mapping #%d in %s", i, filename)
+		}
+	}
+	return all, nil
+}
+
+// tokenHolding is the amount of ETH, or of a token, held by an Ethereum Gateway.
+type tokenHolding struct {
+	Kind     string `json:"kind"`
+	Local    string `json:"local,omitempty"`
+	Contract string `json:"contract,omitempty"`
+	// ETH & ERC20 balance
+	Amount *big.Int `json:"amount,omitempty"`
+	// ERC721 & ERC721X tokens
+	TokenIDs []*big.Int `json:"token_ids,omitempty"`
+	// ERC721X balance of each token in TokenIDs
+	Amounts []*big.Int `json:"amounts,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Returns the total amount held, the number of tokens for ERC721.
+func (h *tokenHolding) total() *big.Int {
+	switch h.Kind {
+	case fundKindERC721:
+		return big.NewInt(int64(len(h.TokenIDs)))
+	case fundKindERC721X:
+		total := new(big.Int)
+		for _, amount := range h.Amounts {
+			total.Add(total, amount)
+		}
+		return total
+	}
+	if h.Amount == nil {
+		return new(big.Int)
+	}
+	return h.Amount
+}
+
+// Returns the balance of each ERC721 & ERC721X token, keyed by token ID.
+func (h *tokenHolding) balances() map[string]*big.Int {
+	balances := map[string]*big.Int{}
+	for i, tokenID := range h.TokenIDs {
+		if h.Kind == fundKindERC721X {
+			balances[tokenID.String()] = h.Amounts[i]
+		} else {
+			balances[tokenID.String()] = big.NewInt(1)
+		}
+	}
+	return balances
+}
+
+// Returns the part of the expected holding that's missing from the current holding.
+func (h *tokenHolding) missingFrom(current *tokenHolding) *tokenHolding {
+	missing := &tokenHolding{Kind: h.Kind, Local: h.Local, Contract: current.Contract}
+	switch h.Kind {
+	case fundKindETH, fundKindERC20:
+		if diff := new(big.Int).Sub(h.total(), current.total()); diff.Sign() > 0 {
+			missing.Amount = diff
+		}
+	case fundKindERC721, fundKindERC721X:
+		held := current.balances()
+		for i, tokenID := range h.TokenIDs {
+			expected := big.NewInt(1)
+			if h.Kind == fundKindERC721X {
+				expected = h.Amounts[i]
+			}
+			diff := new(big.Int).Set(expected)
+			if amount, ok := held[tokenID.String()]; ok {
+				diff.Sub(diff, amount)
+			}
+			if diff.Sign() > 0 {
+				missing.TokenIDs = append(missing.TokenIDs, tokenID)
+				if h.Kind == fundKindERC721X {
+					missing.Amounts = append(missing.Amounts, diff)
+				}
+			}
+		}
+	}
+	return missing
+}
+
+func (h *tokenHolding) isEmpty() bool {
+	return h.total().Sign() == 0
+}
+
+// gatewayFunds reads the funds held by an Ethereum Gateway.
+type gatewayFunds struct {
+	ethClient *ethclient.Client
+	gateway   *ethcontract.MainnetGatewayContract
+	address   common.Address
+}
+
+func newGatewayFunds(ethClient *ethclient.Client, gatewayAddr common.Address) (*gatewayFunds, error) {
+	gw, err := ethcontract.NewMainnetGatewayContract(gatewayAddr, ethClient)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to Ethereum Gateway %s", gatewayAddr.Hex())
+	}
+	return &gatewayFunds{ethClient: ethClient, gateway: gw, address: gatewayAddr}, nil
+}
+
+func (g *gatewayFunds) eth() (*tokenHolding, error) {
+	amount, err := g.gateway.GetETH(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch ETH balance")
+	}
+	return &tokenHolding{Kind: fundKindETH, Amount: amount}, nil
+}
+
+// Figures out the kind of the given token contract, like transfer_gateway_funds.js it checks for
+// ERC721X first (since ERC721X tokens also implement ERC721), then ERC721, and falls back to ERC20.
+// Contracts that don't implement the checked methods revert, which is taken to mean they're not of
+// that kind, any other error is returned so the contract isn't mistaken for an ERC20 token.
+func (g *gatewayFunds) tokenKind(contract common.Address) (string, error) {
+	erc721x, err := ethcontract.NewMainnetERC721XCardsContract(contract, g.ethClient)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to connect to token contract %s", contract.Hex())
+	}
+	ok, err := erc721x.ImplementsERC721X(nil)
+	if err != nil && !isCallReverted(err) {
+		return "", errors.Wrapf(err, "failed to check if %s is an ERC721X contract", contract.Hex())
+	}
+	if ok {
+		return fundKindERC721X, nil
+	}
+	erc721, err := ethcontract.NewSampleERC721MintableToken(contract, g.ethClient)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to connect to token contract %s", contract.Hex())
+	}
+	ok, err = erc721.SupportsInterface(nil, erc721InterfaceID)
+	if err != nil && !isCallReverted(err) {
+		return "", errors.Wrapf(err, "failed to check if %s is an ERC721 contract", contract.Hex())
+	}
+	if ok {
+		return fundKindERC721, nil
+	}
+	return fundKindERC20, nil
+}
+
+// Returns true if the error means a contract call reverted, or returned no data because the
+// contract doesn't have the called method.
+func isCallReverted(err error) bool {
+	msg := err.Error()
+	return err == bind.ErrNoCode || strings.Contains(msg, "execution reverted") ||
+		// Ganache
+		strings.Contains(msg, "VM Exception while processing transaction") ||
+		// Returned by abigen bindings when the call returns no data
+		strings.Contains(msg, "unmarshalling empty output") ||
+		strings.Contains(msg, "abi: attempting to unmarshall an empty string")
+}
+
+// Returns the amount of the given token held by the Gateway. ERC721 & ERC721X tokens are listed
+// via the token contract, and then checked against the Gateway.
+func (g *gatewayFunds) token(kind string, contract common.Address) (*tokenHolding, error) {
+	holding := &tokenHolding{Kind: kind, Contract: contract.Hex()}
+	switch kind {
+	case fundKindERC20:
+		amount, err := g.gateway.GetERC20(nil, contract)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch ERC20 balance of %s", contract.Hex())
+		}
+		holding.Amount = amount
+
+	case fundKindERC721:
+		erc721, err := ethcontract.NewSampleERC721MintableToken(contract, g.ethClient)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to ERC721 contract %s", contract.Hex())
+		}
+		balance, err := erc721.BalanceOf(nil, g.address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch ERC721 balance of %s", contract.Hex())
+		}
+		for i := int64(0); i < balance.Int64(); i++ {
+			tokenID, err := erc721.TokenOfOwnerByIndex(nil, g.address, big.NewInt(i))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch ERC721 token #%d of %s", i, contract.Hex())
+			}
+			held, err := g.gateway.GetERC721(nil, tokenID, contract)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to check ERC721 token %s of %s", tokenID, contract.Hex())
+			}
+			if held {
+				holding.TokenIDs = append(holding.TokenIDs, tokenID)
+			}
+		}
+
+	case fundKindERC721X:
+		erc721x, err := ethcontract.NewMainnetERC721XCardsContract(contract, g.ethClient)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to ERC721X contract %s", contract.Hex())
+		}
+		owned, err := erc721x.TokensOwned(nil, g.address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch ERC721X tokens of %s", contract.Hex())
+		}
+		for _, tokenID := range owned.Indexes {
+			amount, err := g.gateway.GetERC721X(nil, tokenID, contract)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch ERC721X balance of token %s of %s", tokenID, contract.Hex())
+			}
+			if amount.Sign() > 0 {
+				holding.TokenIDs = append(holding.TokenIDs, tokenID)
+				holding.Amounts = append(holding.Amounts, amount)
+			}
+		}
+
+	default:
+		return nil, errors.Errorf("unsupported token kind %s", kind)
+	}
+	return holding, nil
+}
+
+// Returns the ETH held by the Gateway, and the amount of each mapped token it holds. Tokens whose
+// holdings can't be read are included with the error, so that a single broken contract doesn't
+// hide the rest.
+func (g *gatewayFunds) holdings(mappings []contractMapping) ([]*tokenHolding, error) {
+	eth, err := g.eth()
+	if err != nil {
+		return nil, err
+	}
+	holdings := []*tokenHolding{eth}
+	for _, mapping := range mappings {
+		contract := common.HexToAddress(mapping.Foreign)
+		kind, err := g.tokenKind(contract)
+		if err != nil {
+			holdings = append(holdings, &tokenHolding{Contract: contract.Hex(), Local: mapping.Local, Error: err.Error()})
+			continue
+		}
+		holding, err := g.token(kind, contract)
+		if err != nil {
+			holding = &tokenHolding{Kind: kind, Contract: contract.Hex(), Error: err.Error()}
+		}
+		holding.Local = mapping.Local
+		holdings = append(holdings, holding)
+	}
+	return holdings, nil
+}
+
+func printHoldings(holdings []*tokenHolding) {
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tLOCAL\tFOREIGN\tTOKENS\tAMOUNT")
+	for _, h := range holdings {
+		amount := h.total().String()
+		if h.Error != "" {
+			amount = "error: " + h.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", h.Kind, h.Local, h.Contract, len(h.TokenIDs), amount)
+	}
+	w.Flush()
+}
+
+// fundMigrationPlan lists the funds that should be minted to a new Ethereum Gateway to match the
+// funds held by the old one.
+type fundMigrationPlan struct {
+	OldGateway string                `json:"old_gateway"`
+	NewGateway string                `json:"new_gateway"`
+	CreatedAt  time.Time             `json:"created_at"`
+	Entries    []*fundMigrationEntry `json:"entries"`
+}
+
+type fundMigrationEntry struct {
+	// Funds held by the old Gateway
+	tokenHolding
+	// Ethereum contract the funds should be minted on for the new Gateway
+	NewContract string `json:"new_contract,omitempty"`
+}
+
+func loadFundMigrationPlan(filename string) (*fundMigrationPlan, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read migration plan file")
+	}
+	var p fundMigrationPlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	if !common.IsHexAddress(p.NewGateway) {
+		return nil, errors.Errorf("invalid new Gateway address in %s", filename)
+	}
+	return &p, nil
+}
+
+func (p *fundMigrationPlan) save(filename string) error {
+	if cmdFlags.DryRun {
+		printPlanStep("write migration plan to %s", filename)
+		return nil
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "failed to write migration plan file")
+	}
+	fmt.Fprintln(textOut, "wrote to file...", filename)
+	report.FilesWritten = append(report.FilesWritten, filename)
+	return nil
+}
+
+// Returns the funds the new Gateway currently holds for the given plan entry.
+func (e *fundMigrationEntry) current(newGateway *gatewayFunds) (*tokenHolding, error) {
+	if e.Kind == fundKindETH {
+		return newGateway.eth()
+	}
+	return newGateway.token(e.Kind, common.HexToAddress(e.NewContract))
+}
+
+func newGatewayFundsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gateway-funds",
+		Short: "Migrates the funds held by an Ethereum Gateway to a new Gateway deployment",
+	}
+	cmd.AddCommand(
+		newGatewayFundsHoldingsCmd(),
+		newGatewayFundsPlanCmd(),
+		newGatewayFundsMintCmd(),
+		newGatewayFundsReconcileCmd(),
+	)
+	return cmd
+}
+
+type gatewayFundsFlags struct {
+	GatewayAddress string
+	MappingsPath   string
+}
+
+func (f *gatewayFundsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.GatewayAddress, "gateway", "",
+		"Address of the Ethereum Gateway holding the funds, defaults to mainnet_gateway_addr in the deployment file")
+	cmd.Flags().StringVar(&f.MappingsPath, "mappings", "",
+		"JSON file listing the Local/Foreign contract mappings of the Gateway")
+	cmd.MarkFlagRequired("mappings")
+}
+
+// Returns the funds held by the Gateway specified via --gateway for the contracts in --mappings.
+func (f *gatewayFundsFlags) holdings(ethClient *ethclient.Client) (*gatewayFunds, []*tokenHolding, error) {
+	mappings, err := loadContractMappings(f.MappingsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	gatewayAddr, err := resolveEthereumGatewayAddress(f.GatewayAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	funds, err := newGatewayFunds(ethClient, gatewayAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	holdings, err := funds.holdings(mappings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return funds, holdings, nil
+}
+
+func newGatewayFundsHoldingsCmd() *cobra.Command {
+	var flags gatewayFundsFlags
+	cmd := &cobra.Command{
+		Use:   "holdings",
+		Short: "Lists the ETH & mapped tokens held by the Ethereum Gateway",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ethClient, err := dialEthereum()
+			if err != nil {
+				return err
+			}
+			defer ethClient.Close()
+
+			funds, holdings, err := flags.holdings(ethClient)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(textOut, "Ethereum Gateway", funds.address.Hex())
+			printHoldings(holdings)
+			report.Holdings = holdings
+			return nil
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+type gatewayFundsPlanFlags struct {
+	gatewayFundsFlags
+	NewGatewayAddress string
+	NewMappingsPath   string
+	PlanPath          string
+}
+
+var gatewayFundsPlanCmdFlags gatewayFundsPlanFlags
+
+func newGatewayFundsPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Writes a plan for minting the funds held by the Ethereum Gateway to a new Gateway",
+		Long: "Writes a plan for minting the funds held by the Ethereum Gateway to a new Gateway. " +
+			"Each token is minted on the contract that's mapped to the same DAppChain contract in --new-mappings.",
+		RunE: planGatewayFunds,
+	}
+	flags := &gatewayFundsPlanCmdFlags
+	flags.register(cmd)
+	cmd.Flags().StringVar(&flags.NewGatewayAddress, "new-gateway", "", "Address of the new Ethereum Gateway")
+	cmd.Flags().StringVar(&flags.NewMappingsPath, "new-mappings", "",
+		"JSON file listing the Local/Foreign contract mappings of the new Gateway")
+	cmd.Flags().StringVar(&flags.PlanPath, "plan", "", "File to write the migration plan to")
+	cmd.MarkFlagRequired("new-gateway")
+	cmd.MarkFlagRequired("new-mappings")
+	cmd.MarkFlagRequired("plan")
+	return cmd
+}
+
+func planGatewayFunds(cmd *cobra.Command, args []string) error {
+	flags := gatewayFundsPlanCmdFlags
+	if !common.IsHexAddress(flags.NewGatewayAddress) {
+		return errors.Errorf("invalid new Ethereum Gateway address %s", flags.NewGatewayAddress)
+	}
+	newMappings, err := loadContractMappings(flags.NewMappingsPath)
+	if err != nil {
+		return err
+	}
+	newContracts := map[string]string{}
+	for _, mapping := range newMappings {
+		newContracts[strings.ToLower(mapping.Local)] = common.HexToAddress(mapping.Foreign).Hex()
+	}
+
+	ethClient, err := dialEthereum()
+	if err != nil {
+		return err
+	}
+	defer ethClient.Close()
+
+	funds, holdings, err := flags.holdings(ethClient)
+	if err != nil {
+		return err
+	}
+	printHoldings(holdings)
+	report.Holdings = holdings
+
+	plan := &fundMigrationPlan{
+		OldGateway: funds.address.Hex(),
+		NewGateway: common.HexToAddress(flags.NewGatewayAddress).Hex(),
+		CreatedAt:  time.Now().UTC(),
+	}
+	for _, h := range holdings {
+		if h.Error != "" {
+			return errors.Errorf("can't plan migration of %s: %s", h.Contract, h.Error)
+		}
+		if h.isEmpty() {
+			continue
+		}
+		entry := &fundMigrationEntry{tokenHolding: *h}
+		if h.Kind != fundKindETH {
+			newContract, ok := newContracts[strings.ToLower(h.Local)]
+			if !ok {
+				return errors.Errorf("%s (%s) isn't mapped in %s", h.Local, h.Contract, flags.NewMappingsPath)
+			}
+			entry.NewContract = newContract
+		}
+		plan.Entries = append(plan.Entries, entry)
+	}
+	return plan.save(flags.PlanPath)
+}
+
+type gatewayFundsMintFlags struct {
+	PlanPath string
+	ethereumSenderFlags
+}
+
+var gatewayFundsMintCmdFlags gatewayFundsMintFlags
+
+func newGatewayFundsMintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mint",
+		Short: "Mints the funds listed in a migration plan to the new Ethereum Gateway",
+		Long: "Mints the funds listed in a migration plan to the new Ethereum Gateway, funds the new " +
+			"Gateway already holds are skipped, so the command can be re-run if it's interrupted. " +
+			"ETH can't be minted, and must be transferred to the new Gateway separately.",
+		RunE: mintGatewayFunds,
+	}
+	cmd.Flags().StringVar(&gatewayFundsMintCmdFlags.PlanPath, "plan", "", "Migration plan file")
+	cmd.MarkFlagRequired("plan")
+	gatewayFundsMintCmdFlags.registerSenderFlags(cmd)
+	return cmd
+}
+
+func mintGatewayFunds(cmd *cobra.Command, args []string) error {
+	flags := gatewayFundsMintCmdFlags
+	plan, err := loadFundMigrationPlan(flags.PlanPath)
+	if err != nil {
+		return err
+	}
+	key, err := flags.senderKey()
+	if err != nil {
+		return err
+	}
+	// The mintable token clients only use the Ethereum key of the identity.
+	minter := &loom_client.Identity{MainnetPrivKey: key, MainnetAddr: crypto.PubkeyToAddress(key.PublicKey)}
+	newGatewayAddr := common.HexToAddress(plan.NewGateway)
+	recipient := &loom_client.Identity{MainnetAddr: newGatewayAddr}
+	timeout := time.Duration(flags.ConfirmTimeout) * time.Second
+
+	ethClient, err := dialEthereum()
+	if err != nil {
+		return err
+	}
+	defer ethClient.Close()
+
+	newGateway, err := newGatewayFunds(ethClient, newGatewayAddr)
+	if err != nil {
+		return err
+	}
+	// Lets the minter send all the mints for a token contract at once, rather than one by one. Every
+	// tx sent from the minter must go through it, so the nonces it assigns don't go stale.
+	nonces := client.NewNonceManager(ethClient)
+
+	for _, entry := range plan.Entries {
+		current, err := entry.current(newGateway)
+		if err != nil {
+			return err
+		}
+		missing := entry.missingFrom(current)
+		if missing.isEmpty() {
+			fmt.Fprintf(textOut, "%s %s already held by new Gateway\n", entry.Kind, entry.Contract)
+			continue
+		}
+		if entry.Kind == fundKindETH {
+			fmt.Fprintf(textOut, "%s wei must be transferred to new Gateway %s manually\n", missing.Amount, plan.NewGateway)
+			continue
+		}
+		if cmdFlags.DryRun {
+			printPlanStep("mint %s %s on %s to new Gateway %s",
+				missing.total(), entry.Kind, entry.NewContract, plan.NewGateway)
+			continue
+		}
+		switch entry.Kind {
+		case fundKindERC20:
+			token, err := client.ConnectToMainnetERC20MintableContract(ethClient, entry.NewContract)
+			if err != nil {
+				return errors.Wrapf(err, "failed to connect to ERC20 contract %s", entry.NewContract)
+			}
+			token.TxTimeout = timeout
+			token.Nonces = nonces
+			if _, err := token.Mint(minter, newGatewayAddr, missing.Amount); err != nil {
+				return errors.Wrapf(err, "failed to mint %s ERC20 on %s", missing.Amount, entry.NewContract)
+			}
+
+		case fundKindERC721:
+			token, err := client.ConnectToMainnetERC721MintableContract(ethClient, entry.NewContract)
+			if err != nil {
+				return errors.Wrapf(err, "failed to connect to ERC721 contract %s", entry.NewContract)
+			}
+			token.TxTimeout = timeout
+			token.Nonces = nonces
+			var mints client.TxGroup
+			for _, tokenID := range missing.TokenIDs {
+				tokenID := tokenID
+				mints.Go(func() (*client.TxResult, error) {
+					result, err := token.Mint(minter, newGatewayAddr, tokenID)
+					return result, errors.Wrapf(err, "failed to mint ERC721 token %s on %s", tokenID, entry.NewContract)
+				})
+			}
+			if _, err := mints.Wait(); err != nil {
+				return err
+			}
+
+		case fundKindERC721X:
+			token, err := client.ConnectToMainnetERC721XContract(ethClient, entry.NewContract)
+			if err != nil {
+				return errors.Wrapf(err, "failed to connect to ERC721X contract %s", entry.NewContract)
+			}
+			token.TxTimeout = timeout
+			token.Nonces = nonces
+			var mints client.TxGroup
+			for i, tokenID := range missing.TokenIDs {
+				tokenID, amount := tokenID, missing.Amounts[i]
+				mints.Go(func() (*client.TxResult, error) {
+					result, err := token.MintTokens(minter, tokenID, amount, recipient)
+					return result, errors.Wrapf(err, "failed to mint ERC721X token %s on %s", tokenID, entry.NewContract)
+				})
+			}
+			if _, err := mints.Wait(); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(textOut, "minted %s %s on %s\n", missing.total(), entry.Kind, entry.NewContract)
+	}
+
+	if cmdFlags.DryRun {
+		return nil
+	}
+	return reconcileGatewayFunds(plan, newGateway)
+}
+
+var gatewayFundsReconcilePlanPath string
+
+func newGatewayFundsReconcileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Compares the funds held by the new Ethereum Gateway to a migration plan",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := loadFundMigrationPlan(gatewayFundsReconcilePlanPath)
+			if err != nil {
+				return err
+			}
+			ethClient, err := dialEthereum()
+			if err != nil {
+				return err
+			}
+			defer ethClient.Close()
+
+			newGateway, err := newGatewayFunds(ethClient, common.HexToAddress(plan.NewGateway))
+			if err != nil {
+				return err
+			}
+			return reconcileGatewayFunds(plan, newGateway)
+		},
+	}
+	cmd.Flags().StringVar(&gatewayFundsReconcilePlanPath, "plan", "", "Migration plan file")
+	cmd.MarkFlagRequired("plan")
+	return cmd
+}
+
+type reconciliationReport struct {
+	Kind        string `json:"kind"`
+	Local       string `json:"local,omitempty"`
+	OldContract string `json:"old_contract,omitempty"`
+	NewContract string `json:"new_contract,omitempty"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+	Missing     string `json:"missing"`
+}
+
+// Prints how much of each plan entry is held by the new Gateway, and fails if anything is missing.
+func reconcileGatewayFunds(plan *fundMigrationPlan, newGateway *gatewayFunds) error {
+	incomplete := 0
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tOLD CONTRACT\tNEW CONTRACT\tEXPECTED\tACTUAL\tMISSING")
+	for _, entry := range plan.Entries {
+		current, err := entry.current(newGateway)
+		if err != nil {
+			return err
+		}
+		missing := entry.missingFrom(current)
+		if !missing.isEmpty() {
+			incomplete++
+		}
+		r := &reconciliationReport{
+			Kind:        entry.Kind,
+			Local:       entry.Local,
+			OldContract: entry.Contract,
+			NewContract: entry.NewContract,
+			Expected:    entry.total().String(),
+			Actual:      current.total().String(),
+			Missing:     missing.total().String(),
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Kind, r.OldContract, r.NewContract, r.Expected, r.Actual, r.Missing)
+		report.Reconciliation = append(report.Reconciliation, r)
+	}
+	w.Flush()
+
+	if incomplete > 0 {
+		return errors.Errorf("new Gateway %s is missing funds for %d of %d entries",
+			plan.NewGateway, incomplete, len(plan.Entries))
+	}
+	fmt.Fprintf(textOut, "new Gateway %s holds all %d entries\n", plan.NewGateway, len(plan.Entries))
+	return nil
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// governanceProposal is a change to the Validator Manager Contract that's signed by each validator
+// on their own machine, and then submitted by anyone once enough validators have signed it.
+type governanceProposal struct {
+	// Name of the contract method: rotateValidators, setQuorum, or setLoom
+	Action      string `json:"action"`
+	Description string `json:"description"`
+	VMCAddress  string `json:"vmc_address"`
+	// Nonce of the contract when the proposal was created, the signatures are only valid at this nonce
+	Nonce string `json:"nonce"`
+	// Hash the validators sign, computed the same way as createMessage in ValidatorManagerContract.sol
+	MessageHash string `json:"message_hash"`
+
+	// Args of rotateValidators
+	Validators []string `json:"validators,omitempty"`
+	Powers     []uint64 `json:"powers,omitempty"`
+	// Args of setQuorum
+	ThresholdNum   uint8 `json:"threshold_num,omitempty"`
+	ThresholdDenom uint8 `json:"threshold_denom,omitempty"`
+	// Args of setLoom
+	LoomAddress string `json:"loom_address,omitempty"`
+
+	Signatures []*proposalSignature `json:"signatures"`
+}
+
+type proposalSignature struct {
+	Validator string `json:"validator"`
+	Signature string `json:"signature"`
+}
+
+// Returns the action described by the proposal.
+func (p *governanceProposal) action() (*vmcAction, error) {
+	switch p.Action {
+	case "rotateValidators":
+		if len(p.Validators) != len(p.Powers) {
+			return nil, errors.Errorf("got %d validators but %d powers", len(p.Validators), len(p.Powers))
+		}
+		validators := make([]common.Address, len(p.Validators))
+		for i, validator := range p.Validators {
+			if !common.IsHexAddress(validator) {
+				return nil, errors.Errorf("invalid validator address %s", validator)
+			}
+			validators[i] = common.HexToAddress(validator)
+		}
+		return newRotateValidatorsAction(validators, p.Powers), nil
+	case "setQuorum":
+		return newSetQuorumAction(p.ThresholdNum, p.ThresholdDenom)
+	case "setLoom":
+		if !common.IsHexAddress(p.LoomAddress) {
+			return nil, errors.Errorf("invalid LOOM token address %s", p.LoomAddress)
+		}
+		return newSetLoomAction(common.HexToAddress(p.LoomAddress)), nil
+	default:
+		return nil, errors.Errorf("invalid action %s, expected rotateValidators, setQuorum, or setLoom", p.Action)
+	}
+}
+
+// Returns the action described by the proposal, and the message the validators must sign to
+// approve it. The message is recomputed from the proposal args, so it's an error if the proposal
+// has no message hash, or if it doesn't match the recomputed message.
+func (p *governanceProposal) message() (*vmcAction, []byte, error) {
+	action, err := p.action()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !common.IsHexAddress(p.VMCAddress) {
+		return nil, nil, errors.Errorf("invalid Validator Manager Contract address %s", p.VMCAddress)
+	}
+	nonce, ok := new(big.Int).SetString(p.Nonce, 10)
+	if !ok {
+		return nil, nil, errors.Errorf("invalid nonce %s", p.Nonce)
+	}
+	set := &validatorSet{Address: common.HexToAddress(p.VMCAddress), Nonce: nonce}
+	message := set.createMessage(action.argsHash)
+	if p.MessageHash == "" {
+		return nil, nil, errors.New("proposal has no message hash")
+	}
+	if !strings.EqualFold(p.MessageHash, hexutil.Encode(message)) {
+		return nil, nil, errors.Errorf("message hash %s doesn't match the proposal, expected %s",
+			p.MessageHash, hexutil.Encode(message))
+	}
+	return action, message, nil
+}
+
+// Prints the proposal, the description is built from the given action rather than taken from the
+// proposal file, since the file may have been tampered with.
+func (p *governanceProposal) print(action *vmcAction) {
+	fmt.Fprintf(textOut, "proposal: %s\n", action.description)
+	fmt.Fprintf(textOut, "Validator Manager Contract: %s, nonce: %s\n", p.VMCAddress, p.Nonce)
+	fmt.Fprintf(textOut, "message hash: %s\n", p.MessageHash)
+	for _, sig := range p.Signatures {
+		fmt.Fprintf(textOut, "signed by %s\n", sig.Validator)
+	}
+}
+
+func loadGovernanceProposal(filename string) (*governanceProposal, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read proposal file")
+	}
+	var p governanceProposal
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	return &p, nil
+}
+
+func (p *governanceProposal) save(filename string) error {
+	if cmdFlags.DryRun {
+		printPlanStep("write proposal to %s", filename)
+		return nil
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "failed to write proposal file")
+	}
+	fmt.Fprintln(textOut, "wrote to file...", filename)
+	report.FilesWritten = append(report.FilesWritten, filename)
+	return nil
+}
+
+func newGovernanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "governance",
+		Short: "Creates, signs, and submits proposals to change the Validator Manager Contract on Ethereum",
+	}
+	cmd.AddCommand(
+		newGovernanceProposeCmd(),
+		newGovernanceSignCmd(),
+		newGovernanceSubmitCmd(),
+	)
+	return cmd
+}
+
+type governanceProposeFlags struct {
+	validatorSignerFlags
+	ProposalFile string
+}
+
+var governanceProposeCmdFlags governanceProposeFlags
+
+func newGovernanceProposeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "propose",
+		Short: "Writes an unsigned proposal file for a Validator Manager Contract change",
+	}
+	var rotateArgs rotateValidatorsArgs
+	rotateCmd := &cobra.Command{
+		Use:   "rotate-validators",
+		Short: "Proposes a new validator set",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			validators, powers, err := rotateArgs.newValidatorSet()
+			if err != nil {
+				return err
+			}
+			p := &governanceProposal{Action: "rotateValidators", Powers: powers}
+			for _, validator := range validators {
+				p.Validators = append(p.Validators, validator.Hex())
+			}
+			return propose(p)
+		},
+	}
+	rotateArgs.register(rotateCmd)
+
+	var quorumArgs setQuorumArgs
+	quorumCmd := &cobra.Command{
+		Use:   "set-quorum",
+		Short: "Proposes a new threshold fraction",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return propose(&governanceProposal{
+				Action:         "setQuorum",
+				ThresholdNum:   quorumArgs.Num,
+				ThresholdDenom: quorumArgs.Denom,
+			})
+		},
+	}
+	quorumArgs.register(quorumCmd)
+
+	var loomArgs setLoomArgs
+	loomCmd := &cobra.Command{
+		Use:   "set-loom",
+		Short: "Proposes a new LOOM token address",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return propose(&governanceProposal{Action: "setLoom", LoomAddress: loomArgs.LoomAddress})
+		},
+	}
+	loomArgs.register(loomCmd)
+
+	for _, subCmd := range []*cobra.Command{rotateCmd, quorumCmd, loomCmd} {
+		governanceProposeCmdFlags.registerVMCFlags(subCmd)
+		subCmd.Flags().StringVar(&governanceProposeCmdFlags.ProposalFile, "proposal", "",
+			"File the proposal should be written to")
+		subCmd.MarkFlagRequired("proposal")
+		cmd.AddCommand(subCmd)
+	}
+	return cmd
+}
+
+// Binds the proposal to the current nonce of the Validator Manager Contract, and writes it out.
+func propose(p *governanceProposal) error {
+	flags := governanceProposeCmdFlags
+	action, err := p.action()
+	if err != nil {
+		return err
+	}
+
+	vmcAddr, err := flags.vmcAddress()
+	if err != nil {
+		return err
+	}
+	ethClient, vmc, err := dialVMC(vmcAddr)
+	if err != nil {
+		return err
+	}
+	defer ethClient.Close()
+
+	current, err := loadValidatorSet(vmc, vmcAddr)
+	if err != nil {
+		return err
+	}
+	current.print()
+
+	p.Description = action.description
+	p.VMCAddress = vmcAddr.Hex()
+	p.Nonce = current.Nonce.String()
+	p.MessageHash = hexutil.Encode(current.createMessage(action.argsHash))
+	p.Signatures = []*proposalSignature{}
+	p.print(action)
+	return p.save(flags.ProposalFile)
+}
+
+type governanceSignFlags struct {
+	validatorSignerFlags
+	ProposalFile string
+}
+
+var governanceSignCmdFlags governanceSignFlags
+
+func newGovernanceSignCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Adds validator signatures to a proposal file, doesn't need access to Ethereum",
+		RunE:  governanceSign,
+	}
+	governanceSignCmdFlags.registerSignerFlags(cmd)
+	cmd.Flags().StringVar(&governanceSignCmdFlags.ProposalFile, "proposal", "", "Proposal file to sign")
+	cmd.MarkFlagRequired("proposal")
+	return cmd
+}
+
+func governanceSign(cmd *cobra.Command, args []string) error {
+	flags := governanceSignCmdFlags
+	p, err := loadGovernanceProposal(flags.ProposalFile)
+	if err != nil {
+		return err
+	}
+	// Validators sign the message recomputed from the proposal args, not the hash in the file
+	action, message, err := p.message()
+	if err != nil {
+		return err
+	}
+	keys, err := flags.signerKeys()
+	if err != nil {
+		return err
+	}
+	p.print(action)
+
+	for _, key := range keys {
+		signer := crypto.PubkeyToAddress(key.PublicKey)
+		sig, err := signVMCMessage(message, key)
+		if err != nil {
+			return errors.Wrapf(err, "failed to sign proposal with key of %s", signer.Hex())
+		}
+		ps := &proposalSignature{Validator: signer.Hex(), Signature: hexutil.Encode(sig)}
+		replaced := false
+		for i := range p.Signatures {
+			if common.HexToAddress(p.Signatures[i].Validator) == signer {
+				p.Signatures[i] = ps
+				replaced = true
+			}
+		}
+		if !replaced {
+			p.Signatures = append(p.Signatures, ps)
+		}
+		fmt.Fprintf(textOut, "signed by %s\n", signer.Hex())
+	}
+	return p.save(flags.ProposalFile)
+}
+
+type governanceSubmitFlags struct {
+	validatorSignerFlags
+	ProposalFile string
+}
+
+var governanceSubmitCmdFlags governanceSubmitFlags
+
+func newGovernanceSubmitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit",
+		Short: "Submits a signed proposal to the Validator Manager Contract",
+		RunE:  governanceSubmit,
+	}
+	governanceSubmitCmdFlags.registerSenderFlags(cmd)
+	cmd.Flags().StringVar(&governanceSubmitCmdFlags.ProposalFile, "proposal", "", "Signed proposal file to submit")
+	cmd.MarkFlagRequired("proposal")
+	return cmd
+}
+
+func governanceSubmit(cmd *cobra.Command, args []string) error {
+	flags := governanceSubmitCmdFlags
+	p, err := loadGovernanceProposal(flags.ProposalFile)
+	if err != nil {
+		return err
+	}
+	action, message, err := p.message()
+	if err != nil {
+		return err
+	}
+	p.print(action)
+
+	var sigs [][]byte
+	for _, ps := range p.Signatures {
+		sig, err := hexutil.Decode(ps.Signature)
+		if err != nil {
+			return errors.Wrapf(err, "invalid signature of %s", ps.Validator)
+		}
+		signer, err := recoverVMCMessageSigner(message, sig)
+		if err != nil {
+			return errors.Wrapf(err, "invalid signature of %s", ps.Validator)
+		}
+		if signer != common.HexToAddress(ps.Validator) {
+			return errors.Errorf("signature of %s was signed by %s", ps.Validator, signer.Hex())
+		}
+		sigs = append(sigs, sig)
+	}
+
+	senderKey, err := flags.senderKey()
+	if err != nil {
+		return err
+	}
+
+	vmcAddr := common.HexToAddress(p.VMCAddress)
+	ethClient, vmc, err := dialVMC(vmcAddr)
+	if err != nil {
+		return err
+	}
+	defer ethClient.Close()
+
+	current, err := loadValidatorSet(vmc, vmcAddr)
+	if err != nil {
+		return err
+	}
+	if current.Nonce.String() != p.Nonce {
+		return errors.Errorf(
+			"proposal was created at nonce %s but the Validator Manager Contract is at nonce %v, a new proposal must be signed",
+			p.Nonce, current.Nonce,
+		)
+	}
+	fmt.Fprintln(textOut, "before:")
+	current.print()
+
+	validatorSigs, err := current.collectSignatures(message, sigs)
+	if err != nil {
+		return err
+	}
+	return submitVMCAction(ethClient, vmc, current, action, validatorSigs, senderKey, flags.ConfirmTimeout)
+}
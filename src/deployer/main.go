@@ -1,28 +1,16 @@
 package main
 
 import (
-	"encoding/hex"
 	"fmt"
 	"gateway"
-	"io/ioutil"
 	"os"
-	"path"
-	"path/filepath"
-	"strings"
 	"time"
 
-	bnbclient "github.com/binance-chain/go-sdk/client"
-	bnbtypes "github.com/binance-chain/go-sdk/common/types"
-	"github.com/binance-chain/go-sdk/keys"
-	"github.com/ethereum/go-ethereum/common"
-	loom "github.com/loomnetwork/go-loom"
-	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
 	loom_client "github.com/loomnetwork/go-loom/client"
 	"github.com/loomnetwork/go-loom/client/erc20"
 	gw "github.com/loomnetwork/go-loom/client/gateway"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 type RootCmdFlags struct {
@@ -31,6 +19,8 @@ type RootCmdFlags struct {
 	DAppChainContractNames     []string
 	EthereumDeploymentInfoPath string
 	ContractDir                string
+	DryRun                     bool
+	Output                     string
 }
 
 var cmdFlags RootCmdFlags
@@ -48,6 +38,7 @@ func newDeployTronCmd() *cobra.Command {
 }
 
 var mapContractsTimeout int
+var mappingManifestPath string
 
 func newMapContractsCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -57,6 +48,9 @@ func newMapContractsCmd() *cobra.Command {
 	}
 	cmd.Flags().IntVar(&mapContractsTimeout, "timeout", 10,
 		"Max number of seconds to wait for Oracle to confirm contract mapping.")
+	cmd.Flags().StringVar(&mappingManifestPath, "manifest", "",
+		"YAML or JSON file listing the contract mappings, e.g. e2e_config/contract_mappings.yml")
+	cmd.MarkFlagRequired("manifest")
 
 	return cmd
 }
@@ -69,6 +63,9 @@ func newMapTronContractsCmd() *cobra.Command {
 	}
 	cmd.Flags().IntVar(&mapContractsTimeout, "timeout", 10,
 		"Max number of seconds to wait for Oracle to confirm contract mapping.")
+	cmd.Flags().StringVar(&mappingManifestPath, "manifest", "",
+		"YAML or JSON file listing the contract mappings, e.g. e2e_config/contract_mappings.yml")
+	cmd.MarkFlagRequired("manifest")
 
 	return cmd
 }
@@ -81,22 +78,13 @@ func newMapBinanceContractsCmd() *cobra.Command {
 	}
 	cmd.Flags().IntVar(&mapContractsTimeout, "timeout", 10,
 		"Max number of seconds to wait for Oracle to confirm contract mapping.")
+	cmd.Flags().StringVar(&mappingManifestPath, "manifest", "",
+		"YAML or JSON file listing the contract mappings, e.g. e2e_config/contract_mappings.yml")
+	cmd.MarkFlagRequired("manifest")
 
 	return cmd
 }
 
-var privateKeyFile string
-
-func newIssueTokenCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "bnb-issue-token",
-		Short: "Issue token on BNB network",
-		RunE:  bnbIssueToken,
-	}
-	cmd.Flags().StringVar(&privateKeyFile, "private-key", "", "Private key file")
-	return cmd
-}
-
 func mapContracts(cmd *cobra.Command, args []string) error {
 	dAppChainContracts := map[string]bool{}
 	if len(cmdFlags.DAppChainContractNames) > 0 {
@@ -114,19 +102,12 @@ func mapContracts(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to parse loom config")
 	}
 
-	ethKey, dappchainKey := gateway.GetKeys("dan")
-	erc721Creator, err := loom_client.CreateIdentityStr(ethKey, dappchainKey, loomCfg.ChainID)
+	manifest, err := loadContractMappingManifest(mappingManifestPath)
 	if err != nil {
-		return errors.Wrap(err, "failed to create identity3")
-	}
-
-	ethKey, dappchainKey = gateway.GetKeys("trudy")
-	erc20Creator, err := loom_client.CreateIdentityStr(ethKey, dappchainKey, loomCfg.ChainID)
-	if err != nil {
-		return errors.Wrap(err, "failed to create identity4")
+		return err
 	}
 
-	deploymentInfo, err := parseEthereumDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+	deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
 	if err != nil {
 		return errors.Wrap(err, "failed to load deployment info file")
 	}
@@ -142,187 +123,21 @@ func mapContracts(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to connect to Gateway on DAppChain")
 	}
 
-	oracleWaitTime := time.Duration(mapContractsTimeout) * time.Second
-
-	var contractMappingSub *gw.EventSub
-	contractMappingConfirmedCh := make(chan *tgtypes.TransferGatewayContractMappingConfirmed, 1)
-
-	if len(dAppChainContracts) > 0 {
-		contractMappingSub, err = loomGateway.WatchContractMappingConfirmed(contractMappingConfirmedCh)
-		if err != nil {
-			return errors.Wrap(err, "failed to subscribe to DAppChain events")
-		}
-	}
-
-	if dAppChainContracts["SampleERC721Token"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_crypto_cards_addr")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		ethContractAddress := deploymentInfo.GetString("mainnet_crypto_cards_addr")
-		ethContractTxHash := deploymentInfo.GetString("mainnet_crypto_cards_tx")
-		if !common.IsHexAddress(ethContractAddress) || ethContractTxHash == "" {
-			return errors.New("missing Ethereum address and/or tx hash for ERC721 contract")
-		}
-
-		err = loomGateway.AddContractMapping(
-			common.HexToAddress(ethContractAddress), loomLocalContractAddress,
-			erc721Creator, ethContractTxHash,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC721 contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC721 contracts")
-		}
-	}
-
-	if dAppChainContracts["SampleERC721XToken"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_SampleERC721XToken_1")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		ethContractAddress := deploymentInfo.GetString("mainnet_erc721x_cards_addr")
-		ethContractTxHash := deploymentInfo.GetString("mainnet_erc721x_cards_tx")
-		if !common.IsHexAddress(ethContractAddress) || ethContractTxHash == "" {
-			return errors.New("missing Ethereum address and/or tx hash for ERC721X contract")
-		}
-
-		err = loomGateway.AddContractMapping(
-			common.HexToAddress(ethContractAddress), loomLocalContractAddress,
-			erc721Creator, ethContractTxHash,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC721X contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC721X contracts")
-		}
-	}
-
-	if dAppChainContracts["SampleERC20Token"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_SampleERC20Token_1")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		ethContractAddress := deploymentInfo.GetString("mainnet_game_token_addr")
-		ethContractTxHash := deploymentInfo.GetString("mainnet_game_token_tx")
-		if !common.IsHexAddress(ethContractAddress) || ethContractTxHash == "" {
-			return errors.New("missing Ethereum address and/or tx hash for ERC20 contract")
-		}
-
-		err = loomGateway.AddContractMapping(
-			common.HexToAddress(ethContractAddress), loomLocalContractAddress,
-			erc20Creator, ethContractTxHash,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC20 contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC20 contracts")
-		}
-	}
-
-	if dAppChainContracts["SampleERC20Token2"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_SampleERC20Token_2")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		ethContractAddress := deploymentInfo.GetString("mainnet_erc20_mintable_token_addr")
-		ethContractTxHash := deploymentInfo.GetString("mainnet_erc20_mintable_token_tx")
-		if !common.IsHexAddress(ethContractAddress) || ethContractTxHash == "" {
-			return errors.New("missing Ethereum address and/or tx hash for ERC20 contract")
-		}
-
-		err = loomGateway.AddContractMapping(
-			common.HexToAddress(ethContractAddress), loomLocalContractAddress,
-			erc20Creator, ethContractTxHash,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC20 contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC20 contracts")
-		}
+	resolver := &contractMappingResolver{
+		chain:          ethereumChain,
+		chainID:        loomCfg.ChainID,
+		loomClient:     loomClient,
+		deploymentInfo: deploymentInfo,
+		createIdentity: newTestAccountIdentityFunc(ethereumChain, loomCfg.ChainID),
 	}
-
-	if dAppChainContracts["SampleERC721Token2"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_erc721_mintable_token_addr")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		ethContractAddress := deploymentInfo.GetString("mainnet_erc721_mintable_token_addr")
-		ethContractTxHash := deploymentInfo.GetString("mainnet_erc721_mintable_token_tx")
-		if !common.IsHexAddress(ethContractAddress) || ethContractTxHash == "" {
-			return errors.New("missing Ethereum address and/or tx hash for ERC721 contract")
-		}
-
-		err = loomGateway.AddContractMapping(
-			common.HexToAddress(ethContractAddress), loomLocalContractAddress,
-			erc721Creator, ethContractTxHash,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC721 contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC721 contracts")
-		}
+	mappings, err := resolver.resolveAll(manifest.Chain(ethereumChain), dAppChainContracts)
+	if err != nil {
+		return err
 	}
 
-	if contractMappingSub != nil {
-		contractMappingSub.Close()
-	}
-	return nil
+	return addContractMappings(resolver, loomGateway, mappings, func(m *resolvedContractMapping) error {
+		return loomGateway.AddContractMapping(m.foreignAddr, m.localAddr, m.creator, m.txHash)
+	})
 }
 
 func deployTron(cmd *cobra.Command, args []string) error {
@@ -358,44 +173,61 @@ func deployTron(cmd *cobra.Command, args []string) error {
 			return errors.Wrap(err, "failed to connect to Gateway on DAppChain")
 		}
 
-		if dAppChainContractsToDeploy["TRXToken"] {
-			c, err := erc20.DeployERC20ToDAppChain(
-				loomClient, "TRXToken", loomGateway.Address, erc20Creator.LoomSigner)
-			if err != nil {
-				return errors.Wrap(err, "failed to deploy TRXToken")
-			}
-			fmt.Printf("TRXToken at %v\n", c.Address)
-			// write to file for tron test
-			e2eDir := path.Dir(cmdFlags.EthereumDeploymentInfoPath)
-			if err := os.MkdirAll(e2eDir, 0744); err != nil {
-				return errors.Wrap(err, "failed to create directory")
-			}
-			filename := path.Join(e2eDir, "dapp_trx_token_address")
-			err = ioutil.WriteFile(filename, []byte(c.Address.String()), 0744)
-			if err != nil {
-				return errors.Wrap(err, "failed to write file dapp_trx_token_address")
+		// The DAppChain contract addresses are merged into the deployment file, where
+		// map-tron-contracts will find them.
+		deploymentInfo, err := gateway.LoadOrCreateDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to load deployment info file")
+		}
+
+		contracts := []struct{ name, addrKey string }{
+			{"TRXToken", "loomchain_trx_token_addr"},
+			{"SampleERC20Token", "loomchain_trc20_token_addr"},
+		}
+
+		if cmdFlags.DryRun {
+			for _, contract := range contracts {
+				if dAppChainContractsToDeploy[contract.name] {
+					printPlanStep("deploy %s to DAppChain, signed by trudy (%s), gateway %s, writes %s to %s",
+						contract.name, erc20Creator.LoomAddr.String(), loomGateway.Address.String(),
+						contract.addrKey, cmdFlags.EthereumDeploymentInfoPath,
+					)
+				}
 			}
-			fmt.Println("wrote to file...", filename)
+			return nil
 		}
 
-		if dAppChainContractsToDeploy["SampleERC20Token"] {
+		deployed := false
+		for _, contract := range contracts {
+			if !dAppChainContractsToDeploy[contract.name] {
+				continue
+			}
+
+			start := time.Now()
 			c, err := erc20.DeployERC20ToDAppChain(
-				loomClient, "SampleERC20Token", loomGateway.Address, erc20Creator.LoomSigner)
+				loomClient, contract.name, loomGateway.Address, erc20Creator.LoomSigner)
 			if err != nil {
-				return errors.Wrap(err, "failed to deploy SampleERC20Token")
+				return errors.Wrapf(err, "failed to deploy %s", contract.name)
 			}
-			fmt.Printf("SampleERC20Token at %v\n", c.Address)
-			// write to file for tron test
-			e2eDir := path.Dir(cmdFlags.EthereumDeploymentInfoPath)
-			if err := os.MkdirAll(e2eDir, 0744); err != nil {
-				return errors.Wrap(err, "failed to create directory")
+			fmt.Fprintf(textOut, "%s at %v\n", contract.name, c.Address)
+			report.Deployments = append(report.Deployments, &deploymentReport{
+				Name:       contract.name,
+				Chain:      "dappchain",
+				Address:    c.Address.String(),
+				DurationMs: durationMs(start),
+			})
+			if err := deploymentInfo.Set(contract.addrKey, c.Address.Local.Hex()); err != nil {
+				return err
 			}
-			filename := path.Join(e2eDir, "dapp_trc20_token_address")
-			err = ioutil.WriteFile(filename, []byte(c.Address.String()), 0744)
-			if err != nil {
-				return errors.Wrap(err, "failed to write file dapp_trc20_token_address")
+			deployed = true
+		}
+
+		if deployed {
+			if err := deploymentInfo.Save(); err != nil {
+				return errors.Wrap(err, "failed to update deployment info file")
 			}
-			fmt.Println("wrote to file...", filename)
+			fmt.Fprintln(textOut, "wrote to file...", cmdFlags.EthereumDeploymentInfoPath)
+			report.FilesWritten = append(report.FilesWritten, cmdFlags.EthereumDeploymentInfoPath)
 		}
 	}
 
@@ -419,19 +251,12 @@ func mapTronContracts(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to parse loom config")
 	}
 
-	tronKey, dappchainKey := gateway.GetTronKeys("trudy")
-	erc20Creator, err := loom_client.CreateIdentityStr(tronKey, dappchainKey, loomCfg.ChainID)
-	if err != nil {
-		return errors.Wrap(err, "failed to create identity6")
-	}
-
-	tronKey, dappchainKey = gateway.GetTronKeys("gateway_owner")
-	gatewayOwner, err := loom_client.CreateIdentityStr(tronKey, dappchainKey, loomCfg.ChainID)
+	manifest, err := loadContractMappingManifest(mappingManifestPath)
 	if err != nil {
-		return errors.Wrap(err, "failed to create identity7")
+		return err
 	}
 
-	deploymentInfo, err := parseEthereumDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+	deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
 	if err != nil {
 		return errors.Wrap(err, "failed to load deployment info file")
 	}
@@ -447,68 +272,24 @@ func mapTronContracts(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to connect to Gateway on DAppChain")
 	}
 
-	oracleWaitTime := time.Duration(mapContractsTimeout) * time.Second
-
-	var contractMappingSub *gw.EventSub
-	contractMappingConfirmedCh := make(chan *tgtypes.TransferGatewayContractMappingConfirmed, 1)
-
-	if len(dAppChainContracts) > 0 {
-		contractMappingSub, err = loomGateway.WatchContractMappingConfirmed(contractMappingConfirmedCh)
-		if err != nil {
-			return errors.Wrap(err, "failed to subscribe to DAppChain events")
-		}
+	resolver := &contractMappingResolver{
+		chain:          tronChain,
+		chainID:        loomCfg.ChainID,
+		loomClient:     loomClient,
+		deploymentInfo: deploymentInfo,
+		createIdentity: newTestAccountIdentityFunc(tronChain, loomCfg.ChainID),
 	}
-
-	if dAppChainContracts["TRXToken"] {
-		TRXToken, err := erc20.ConnectERC20ToDAppChain(loomClient, "TRXToken")
-		if err != nil {
-			return err
-		}
-		fakeTRXContractAddress := loom.MustParseAddress("tron:0x0000000000000000000000000000000000000001")
-		err = loomGateway.AddAuthorizedTronContractMapping(
-			common.HexToAddress(fakeTRXContractAddress.Local.Hex()), TRXToken.Address,
-			gatewayOwner,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC20 contracts")
-		}
+	mappings, err := resolver.resolveAll(manifest.Chain(tronChain), dAppChainContracts)
+	if err != nil {
+		return err
 	}
 
-	if dAppChainContracts["SampleERC20Token"] {
-		loomCoin, err := erc20.ConnectERC20ToDAppChain(loomClient, "SampleERC20Token")
-		if err != nil {
-			return err
-		}
-
-		tronContractAddress := deploymentInfo.GetString("loomtoken_addr")
-		tronContractAddress = strings.TrimPrefix(tronContractAddress, "41")
-		if !common.IsHexAddress(tronContractAddress) {
-			return errors.New("missing Tron address for ERC20 contract")
+	return addContractMappings(resolver, loomGateway, mappings, func(m *resolvedContractMapping) error {
+		if m.Authorized {
+			return loomGateway.AddAuthorizedTronContractMapping(m.foreignAddr, m.localAddr, m.creator)
 		}
-
-		// we are not able txHash when we deploy contract via tronbox.
-		// so the hacky way to get gateway checking it to use tronContractAddress
-		// as a key for the gateway.
-		err = loomGateway.AddTronContractMapping(
-			common.HexToAddress(tronContractAddress), loomCoin.Address,
-			erc20Creator, tronContractAddress,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map ERC20 contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC20 contracts")
-		}
-	}
-
-	if contractMappingSub != nil {
-		contractMappingSub.Close()
-	}
-	return nil
+		return loomGateway.AddTronContractMapping(m.foreignAddr, m.localAddr, m.creator, m.txHash)
+	})
 }
 
 func mapBinanceContracts(cmd *cobra.Command, args []string) error {
@@ -528,30 +309,7 @@ func mapBinanceContracts(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to parse loom config")
 	}
 
-	bnbKey, dappchainKey := gateway.GetBnbKeys("gateway_owner")
-	keyManager, err := keys.NewMnemonicKeyManager(bnbKey)
-	if err != nil {
-		return err
-	}
-	privkey, err := keyManager.ExportAsPrivateKey()
-	if err != nil {
-		return err
-	}
-	gatewayOwner, err := loom_client.CreateIdentityStr(privkey, dappchainKey, loomCfg.ChainID)
-	if err != nil {
-		return errors.Wrap(err, "failed to create identity")
-	}
-
-	bnbKey, dappchainKey = gateway.GetBnbKeys("token_owner")
-	keyManager, err = keys.NewMnemonicKeyManager(bnbKey)
-	if err != nil {
-		return err
-	}
-	privkey, err = keyManager.ExportAsPrivateKey()
-	if err != nil {
-		return err
-	}
-	tokenOwner, err := loom_client.CreateIdentityStr(privkey, dappchainKey, loomCfg.ChainID)
+	manifest, err := loadContractMappingManifest(mappingManifestPath)
 	if err != nil {
 		return err
 	}
@@ -567,117 +325,36 @@ func mapBinanceContracts(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to connect to Gateway on DAppChain")
 	}
 
-	oracleWaitTime := time.Duration(mapContractsTimeout) * time.Second
-
-	var contractMappingSub *gw.EventSub
-	contractMappingConfirmedCh := make(chan *tgtypes.TransferGatewayContractMappingConfirmed, 1)
-
-	if len(dAppChainContracts) > 0 {
-		contractMappingSub, err = loomGateway.WatchContractMappingConfirmed(contractMappingConfirmedCh)
-		if err != nil {
-			return errors.Wrap(err, "failed to subscribe to DAppChain events")
-		}
-	}
-
-	contractDir := cmdFlags.ContractDir
-	if contractDir == "" {
-		contractDir, _ = os.Getwd()
-	}
-
-	deploymentInfo, err := parseEthereumDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+	deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
 	if err != nil {
 		return errors.Wrap(err, "failed to load deployment info file")
 	}
 
-	if dAppChainContracts["BNBToken"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_bnb_token_addr")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		// Fake token contract that will be mapped to native BNB token on Binance Dex
-		fakeMainnetBNBTokenAddress := loom.MustParseAddress("binance:0x0000000000000000000000000000000000424e42")
-		err = loomGateway.AddAuthorizedBinanceContractMapping(
-			common.HexToAddress(fakeMainnetBNBTokenAddress.Local.Hex()), loomLocalContractAddress,
-			gatewayOwner,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map BNBToken contracts")
-		}
-		fmt.Printf("mapped %s <==> %s\n", localContractAddress, fakeMainnetBNBTokenAddress.String())
+	resolver := &contractMappingResolver{
+		chain:          binanceChain,
+		chainID:        loomCfg.ChainID,
+		loomClient:     loomClient,
+		deploymentInfo: deploymentInfo,
+		createIdentity: newTestAccountIdentityFunc(binanceChain, loomCfg.ChainID),
 	}
-
-	if dAppChainContracts["SampleBEP2Token"] {
-		localContractAddress := deploymentInfo.GetString("loomchain_bep2_token_addr")
-		local, err := loom.LocalAddressFromHexString(localContractAddress)
-		if err != nil {
-			return errors.Wrap(err, "parsing address fail")
-		}
-		loomLocalContractAddress := loom.Address{
-			ChainID: loomCfg.ChainID,
-			Local:   local,
-		}
-
-		// MOOL-CBC is assumed to have already issued on BinanceChain
-		tokenNameHex := hex.EncodeToString([]byte("MOOL-CBC"))
-		fakeMainnetMoolTokenAddress := common.HexToAddress(tokenNameHex)
-		err = loomGateway.AddBinanceContractMapping(
-			fakeMainnetMoolTokenAddress, loomLocalContractAddress,
-			tokenOwner,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to map SampleBEP2Token contracts")
-		}
-
-		// Let the Oracle fetch pending contract mappings and confirm them
-		select {
-		case <-contractMappingConfirmedCh:
-		case <-time.After(oracleWaitTime):
-			return errors.New("timeout while waiting for ContractMappingConfirmed event for ERC20 contracts")
-		}
-
-		fmt.Printf("mapped %s <==> %s\n", localContractAddress, fakeMainnetMoolTokenAddress.String())
-	}
-
-	if contractMappingSub != nil {
-		contractMappingSub.Close()
-	}
-
-	return nil
-}
-
-func bnbIssueToken(cmd *cobra.Command, args []string) error {
-	keyManager, err := keys.NewPrivateKeyManager("466090730f432eaa3a412ca2431e829999f781adc65a5917a27def68e6928e58")
+	mappings, err := resolver.resolveAll(manifest.Chain(binanceChain), dAppChainContracts)
 	if err != nil {
 		return err
 	}
 
-	client, err := bnbclient.NewDexClient("testnet-dex.binance.org", bnbtypes.TestNetwork, keyManager)
-	if err != nil {
-		return err
-	}
-	// supply needs to be multiplied by 10^8
-	issue, err := client.IssueToken("MOOL_Token", "MOOL", 100000000000000000, true, true)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("result: %+v", issue)
-	return nil
+	return addContractMappings(resolver, loomGateway, mappings, func(m *resolvedContractMapping) error {
+		if m.Authorized {
+			return loomGateway.AddAuthorizedBinanceContractMapping(m.foreignAddr, m.localAddr, m.creator)
+		}
+		return loomGateway.AddBinanceContractMapping(m.foreignAddr, m.localAddr, m.creator)
+	})
 }
 
-func parseEthereumDeploymentInfo(filename string) (*viper.Viper, error) {
-	v := viper.New()
-	name := filepath.Base(filename)
-	name = strings.TrimSuffix(name, filepath.Ext(name))
-	dir := filepath.Dir(filename)
-	v.SetConfigName(name)
-	v.AddConfigPath(dir)
-	return v, v.ReadInConfig()
+// Prints a step the command would perform if it wasn't running in dry-run mode.
+func printPlanStep(format string, args ...interface{}) {
+	step := fmt.Sprintf(format, args...)
+	report.Plan = append(report.Plan, step)
+	fmt.Fprintln(textOut, "[dry-run]", step)
 }
 
 func main() {
@@ -688,20 +365,43 @@ func main() {
 	pflags.StringSliceVar(&cmdFlags.EthereumContractNames, "ethereum-contracts", nil, "Names of contracts to deploy to Ethereum network")
 	pflags.StringSliceVar(&cmdFlags.DAppChainContractNames, "dappchain-contracts", nil, "Names of contracts to deploy to DAppChain")
 	pflags.StringVar(&cmdFlags.ContractDir, "contract-dir", "", "Directory containing contract abi and bin. Default to current dir")
+	pflags.StringVar(&cmdFlags.Output, "output", outputText, "Output format: text or json")
+	pflags.BoolVar(&cmdFlags.DryRun, "dry-run", false, "Resolve all inputs and print what would be done without sending any txs")
 	RootCmd.MarkFlagRequired("loom-dir")
 	RootCmd.MarkFlagRequired("deployment-file")
 	RootCmd.MarkFlagFilename("deployment-file")
 
 	RootCmd.AddCommand(
+		newDeployEthereumCmd(),
 		newMapContractsCmd(),
 		newDeployTronCmd(),
 		newMapTronContractsCmd(),
 		newIssueTokenCmd(),
 		newMapBinanceContractsCmd(),
+		newStatusCmd(),
+		newRotateValidatorsCmd(),
+		newSetQuorumCmd(),
+		newSetLoomCmd(),
+		newGovernanceCmd(),
+		newGatewayAdminCmd(),
+		newScanNoncesCmd(),
+		newGatewayFundsCmd(),
+		newValidatorsCmd(),
+		newWithdrawCmd(),
+		newWithdrawalsCmd(),
+		newDepositCmd(),
 	)
 
-	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	RootCmd.PersistentPreRunE = startReport
+
+	err := RootCmd.Execute()
+	if reportErr := finishReport(err); reportErr != nil {
+		fmt.Fprintln(os.Stderr, "failed to write report:", reportErr)
+	}
+	if err != nil {
+		if cmdFlags.Output != outputJSON {
+			fmt.Println(err)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,572 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"gateway"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/binance-chain/go-sdk/keys"
+	"github.com/ethereum/go-ethereum/common"
+	loom "github.com/loomnetwork/go-loom"
+	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
+	loom_client "github.com/loomnetwork/go-loom/client"
+	gw "github.com/loomnetwork/go-loom/client/gateway"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Foreign chains the DAppChain Gateways can map contracts to, these match the top-level sections
+// of the contract mapping manifest.
+const (
+	ethereumChain = "ethereum"
+	tronChain     = "tron"
+	binanceChain  = "binance"
+)
+
+// Chain IDs the DAppChain Gateways use for foreign contract addresses.
+var foreignChainIDs = map[string]string{
+	ethereumChain: "eth",
+	tronChain:     "tron",
+	binanceChain:  "binance",
+}
+
+var contractMappingKinds = map[string][]string{
+	ethereumChain: {"eth", "erc20", "erc721", "erc721x"},
+	tronChain:     {"trx", "trc20"},
+	binanceChain:  {"bnb", "bep2"},
+}
+
+// ContractMapping describes a single DAppChain <-> foreign chain contract mapping that should be
+// added to one of the DAppChain Gateways.
+type ContractMapping struct {
+	// Name of the DAppChain contract, matched against --dappchain-contracts.
+	Name string `mapstructure:"name"`
+	// Token kind, e.g. erc20, erc721, erc721x, trx, bnb.
+	Kind string `mapstructure:"kind"`
+	// Name of the test account that created the contracts.
+	Creator string `mapstructure:"creator"`
+	// Authorized mappings are added by the Gateway owner and don't need to be confirmed by the Oracle.
+	Authorized bool `mapstructure:"authorized"`
+
+	// The DAppChain contract address is either read from the deployment file, or resolved from
+	// the name the contract was registered under.
+	LocalAddrKey  string `mapstructure:"local_addr_key"`
+	LocalContract string `mapstructure:"local_contract"`
+
+	// The foreign contract address is either read from the deployment file, specified verbatim,
+	// or derived from a Binance Chain token symbol (which may itself be read from the deployment file).
+	ForeignAddrKey   string `mapstructure:"foreign_addr_key"`
+	ForeignAddr      string `mapstructure:"foreign_addr"`
+	ForeignSymbol    string `mapstructure:"foreign_symbol"`
+	ForeignSymbolKey string `mapstructure:"foreign_symbol_key"`
+	ForeignTxKey     string `mapstructure:"foreign_tx_key"`
+}
+
+// ContractMappingManifest lists the contract mappings for each of the DAppChain Gateways.
+type ContractMappingManifest struct {
+	Ethereum []ContractMapping `mapstructure:"ethereum"`
+	Tron     []ContractMapping `mapstructure:"tron"`
+	Binance  []ContractMapping `mapstructure:"binance"`
+}
+
+// Chain returns the contract mappings for the DAppChain Gateway of the given foreign chain.
+func (m *ContractMappingManifest) Chain(chain string) []ContractMapping {
+	switch chain {
+	case ethereumChain:
+		return m.Ethereum
+	case tronChain:
+		return m.Tron
+	case binanceChain:
+		return m.Binance
+	}
+	return nil
+}
+
+// Loads the YAML or JSON contract mapping manifest.
+func loadContractMappingManifest(filename string) (*ContractMappingManifest, error) {
+	v := viper.New()
+	v.SetConfigFile(filename)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read contract mapping manifest %s", filename)
+	}
+	var manifest ContractMappingManifest
+	if err := v.Unmarshal(&manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse contract mapping manifest %s", filename)
+	}
+
+	for _, chain := range []string{ethereumChain, tronChain, binanceChain} {
+		mappings := manifest.Chain(chain)
+		for i := range mappings {
+			if err := mappings[i].validate(chain); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s contract mapping #%d in %s", chain, i, filename)
+			}
+		}
+	}
+	return &manifest, nil
+}
+
+func (m *ContractMapping) validate(chain string) error {
+	if m.Name == "" {
+		return errors.New("missing name")
+	}
+	if m.Creator == "" {
+		return errors.Errorf("missing creator for %s", m.Name)
+	}
+
+	validKind := false
+	for _, kind := range contractMappingKinds[chain] {
+		if m.Kind == kind {
+			validKind = true
+			break
+		}
+	}
+	if !validKind {
+		return errors.Errorf("invalid kind %q for %s, expected one of %s",
+			m.Kind, m.Name, strings.Join(contractMappingKinds[chain], ", "))
+	}
+
+	if (m.LocalAddrKey == "") == (m.LocalContract == "") {
+		return errors.Errorf("exactly one of local_addr_key or local_contract must be set for %s", m.Name)
+	}
+
+	foreignAddrSources := 0
+	for _, s := range []string{m.ForeignAddrKey, m.ForeignAddr, m.ForeignSymbol, m.ForeignSymbolKey} {
+		if s != "" {
+			foreignAddrSources++
+		}
+	}
+	if foreignAddrSources != 1 {
+		return errors.Errorf(
+			"exactly one of foreign_addr_key, foreign_addr, foreign_symbol, or foreign_symbol_key must be set for %s",
+			m.Name,
+		)
+	}
+	if m.ForeignAddr != "" && !common.IsHexAddress(m.ForeignAddr) {
+		return errors.Errorf("invalid foreign_addr for %s", m.Name)
+	}
+	if (m.ForeignSymbol != "" || m.ForeignSymbolKey != "") && chain != binanceChain {
+		return errors.Errorf("foreign_symbol is only supported by Binance mappings, found in %s", m.Name)
+	}
+
+	if chain == ethereumChain {
+		if m.Authorized {
+			return errors.Errorf("authorized Ethereum mappings are not supported, found in %s", m.Name)
+		}
+		if m.ForeignTxKey == "" {
+			return errors.Errorf("missing foreign_tx_key for %s", m.Name)
+		}
+	}
+	return nil
+}
+
+// resolvedContractMapping is a ContractMapping with all the addresses & accounts looked up.
+type resolvedContractMapping struct {
+	*ContractMapping
+	localAddr   loom.Address
+	foreignAddr common.Address
+	txHash      string
+	creator     *loom_client.Identity
+}
+
+type contractMappingResolver struct {
+	chain          string
+	chainID        string
+	loomClient     *loom_client.DAppChainRPCClient
+	deploymentInfo *gateway.DeploymentInfo
+	// Creates the identity of a test account, the foreign key type depends on the chain.
+	createIdentity func(account string) (*loom_client.Identity, error)
+	identities     map[string]*loom_client.Identity
+}
+
+// Returns a function that creates the identity of a test account, the foreign key of the identity
+// is loaded from test_keys.yml based on the given foreign chain.
+func newTestAccountIdentityFunc(chain, chainID string) func(account string) (*loom_client.Identity, error) {
+	return func(account string) (*loom_client.Identity, error) {
+		switch chain {
+		case tronChain:
+			tronKey, dappchainKey := gateway.GetTronKeys(account)
+			return loom_client.CreateIdentityStr(tronKey, dappchainKey, chainID)
+		case binanceChain:
+			bnbKey, dappchainKey := gateway.GetBnbKeys(account)
+			keyManager, err := keys.NewMnemonicKeyManager(bnbKey)
+			if err != nil {
+				return nil, err
+			}
+			privkey, err := keyManager.ExportAsPrivateKey()
+			if err != nil {
+				return nil, err
+			}
+			return loom_client.CreateIdentityStr(privkey, dappchainKey, chainID)
+		default:
+			ethKey, dappchainKey := gateway.GetKeys(account)
+			return loom_client.CreateIdentityStr(ethKey, dappchainKey, chainID)
+		}
+	}
+}
+
+// Resolves the manifest entries whose name is in the given set of DAppChain contract names,
+// entries are returned in the order they appear in the manifest.
+func (r *contractMappingResolver) resolveAll(
+	mappings []ContractMapping, contractNames map[string]bool,
+) ([]*resolvedContractMapping, error) {
+	var resolved []*resolvedContractMapping
+	for i := range mappings {
+		if !contractNames[mappings[i].Name] {
+			continue
+		}
+		rm, err := r.resolve(&mappings[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve contract mapping for %s", mappings[i].Name)
+		}
+		resolved = append(resolved, rm)
+	}
+	return resolved, nil
+}
+
+func (r *contractMappingResolver) resolve(m *ContractMapping) (*resolvedContractMapping, error) {
+	rm := &resolvedContractMapping{ContractMapping: m}
+
+	if m.LocalAddrKey != "" {
+		localContractAddress := r.deploymentInfo.Get(m.LocalAddrKey)
+		local, err := loom.LocalAddressFromHexString(localContractAddress)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", m.LocalAddrKey)
+		}
+		rm.localAddr = loom.Address{
+			ChainID: r.chainID,
+			Local:   local,
+		}
+	} else {
+		addr, err := r.loomClient.Resolve(m.LocalContract)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve DAppChain contract %s", m.LocalContract)
+		}
+		rm.localAddr = addr
+	}
+
+	var foreignContractAddress string
+	switch {
+	case m.ForeignAddrKey != "":
+		foreignContractAddress = r.deploymentInfo.Get(m.ForeignAddrKey)
+		if r.chain == tronChain {
+			foreignContractAddress = strings.TrimPrefix(foreignContractAddress, "41")
+		}
+		if !common.IsHexAddress(foreignContractAddress) {
+			return nil, errors.Errorf("missing %s address %s", r.chain, m.ForeignAddrKey)
+		}
+		rm.foreignAddr = common.HexToAddress(foreignContractAddress)
+	case m.ForeignAddr != "":
+		foreignContractAddress = m.ForeignAddr
+		rm.foreignAddr = common.HexToAddress(foreignContractAddress)
+	case m.ForeignSymbol != "":
+		// BEP2 tokens don't have contract addresses, so the hex encoded symbol is used instead
+		rm.foreignAddr = common.HexToAddress(hex.EncodeToString([]byte(m.ForeignSymbol)))
+	case m.ForeignSymbolKey != "":
+		symbol := r.deploymentInfo.Get(m.ForeignSymbolKey)
+		if symbol == "" {
+			return nil, errors.Errorf("missing %s token symbol %s", r.chain, m.ForeignSymbolKey)
+		}
+		rm.foreignAddr = common.HexToAddress(hex.EncodeToString([]byte(symbol)))
+	}
+
+	if m.ForeignTxKey != "" {
+		rm.txHash = r.deploymentInfo.Get(m.ForeignTxKey)
+		if rm.txHash == "" {
+			return nil, errors.Errorf("missing %s tx hash %s", r.chain, m.ForeignTxKey)
+		}
+	} else if r.chain == tronChain && !m.Authorized {
+		// we are not able txHash when we deploy contract via tronbox.
+		// so the hacky way to get gateway checking it to use tronContractAddress
+		// as a key for the gateway.
+		rm.txHash = foreignContractAddress
+	}
+
+	if r.identities == nil {
+		r.identities = map[string]*loom_client.Identity{}
+	}
+	creator, ok := r.identities[m.Creator]
+	if !ok {
+		var err error
+		creator, err = r.createIdentity(m.Creator)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create identity for %s", m.Creator)
+		}
+		r.identities[m.Creator] = creator
+	}
+	rm.creator = creator
+	return rm, nil
+}
+
+// Status of a contract mapping submitted to a DAppChain Gateway.
+const (
+	mappingStatusPending   = "pending"
+	mappingStatusConfirmed = "confirmed"
+	mappingStatusExisting  = "already mapped"
+	mappingStatusTimedOut  = "timed out"
+	mappingStatusFailed    = "failed"
+)
+
+// Queries the DAppChain Gateway for existing confirmed or pending mappings of either of the contracts
+// in the given mapping. Returns mappingStatusConfirmed or mappingStatusPending if the given mapping
+// already exists, an empty string if neither contract is mapped, or an error describing the
+// differences if either contract is mapped to some other contract.
+func (r *contractMappingResolver) findExistingMapping(
+	gatewayAddr loom.Address, m *resolvedContractMapping,
+) (string, error) {
+	foreignAddr := loom.Address{
+		ChainID: foreignChainIDs[r.chain],
+		Local:   m.foreignAddr.Bytes(),
+	}
+	byForeign, err := r.getContractMapping(gatewayAddr, m.creator.LoomAddr, foreignAddr)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to look up mapping for %s", foreignAddr.String())
+	}
+	byLocal, err := r.getContractMapping(gatewayAddr, m.creator.LoomAddr, m.localAddr)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to look up mapping for %s", m.localAddr.String())
+	}
+
+	var diffs []string
+	if byForeign.Found && byForeign.MappedAddress != nil {
+		mappedAddr := loom.UnmarshalAddressPB(byForeign.MappedAddress)
+		if mappedAddr.Local.Compare(m.localAddr.Local) != 0 {
+			diffs = append(diffs, fmt.Sprintf(
+				"  %s is mapped to\n  - %s (existing)\n  + %s (manifest)",
+				foreignAddr.String(), mappedAddr.String(), m.localAddr.String(),
+			))
+		}
+	}
+	if byLocal.Found && byLocal.MappedAddress != nil {
+		mappedAddr := loom.UnmarshalAddressPB(byLocal.MappedAddress)
+		if mappedAddr.Local.Compare(foreignAddr.Local) != 0 {
+			diffs = append(diffs, fmt.Sprintf(
+				"  %s is mapped to\n  - %s (existing)\n  + %s (manifest)",
+				m.localAddr.String(), mappedAddr.String(), foreignAddr.String(),
+			))
+		}
+	}
+	if len(diffs) > 0 {
+		return "", errors.Errorf("conflicts with existing mapping\n%s", strings.Join(diffs, "\n"))
+	}
+
+	switch {
+	case !byForeign.Found && !byLocal.Found:
+		return "", nil
+	case byForeign.IsPending || byLocal.IsPending:
+		return mappingStatusPending, nil
+	default:
+		return mappingStatusConfirmed, nil
+	}
+}
+
+func (r *contractMappingResolver) getContractMapping(
+	gatewayAddr, caller, from loom.Address,
+) (*tgtypes.TransferGatewayGetContractMappingResponse, error) {
+	req := &tgtypes.TransferGatewayGetContractMappingRequest{
+		From: from.MarshalPB(),
+	}
+	var resp tgtypes.TransferGatewayGetContractMappingResponse
+	contract := loom_client.NewContract(r.loomClient, gatewayAddr.Local)
+	if _, err := contract.StaticCall("GetContractMapping", req, caller, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+type contractMappingResult struct {
+	mapping     *resolvedContractMapping
+	status      string
+	err         error
+	submittedAt time.Time
+	// Time it took for the mapping to be confirmed, or to fail
+	elapsed time.Duration
+}
+
+// Identifies a contract mapping by the foreign & local contract addresses, the chain IDs are ignored
+// since the foreign chain ID depends on the Gateway the mapping was submitted to.
+func contractMappingKey(foreignAddr, localAddr loom.LocalAddress) string {
+	return strings.ToLower(foreignAddr.Hex() + ":" + localAddr.Hex())
+}
+
+// Submits all the given contract mappings to the DAppChain Gateway, then waits for the Oracle to
+// confirm each non-authorized mapping. Mappings that already exist on the DAppChain Gateway are
+// skipped, and nothing is submitted if any of the mappings conflict with existing ones.
+// ContractMappingConfirmed events are matched to the mapping they confirm, events for any other
+// mapping are ignored. Returns an error if any of the mappings failed or wasn't confirmed in time.
+func addContractMappings(
+	r *contractMappingResolver, loomGateway *gw.DAppChainGateway, mappings []*resolvedContractMapping,
+	addMapping func(m *resolvedContractMapping) error,
+) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	existing := make([]string, len(mappings))
+	conflicts := 0
+	for i, m := range mappings {
+		var err error
+		existing[i], err = r.findExistingMapping(loomGateway.Address, m)
+		if err != nil {
+			fmt.Fprintf(textOut, "%s: %v\n", m.Name, err)
+			recordContractMapping(r.chain, &contractMappingResult{mapping: m, status: mappingStatusFailed, err: err})
+			conflicts++
+		}
+	}
+	if conflicts > 0 {
+		return errors.Errorf("%d contract mapping(s) can't be added to the DAppChain Gateway", conflicts)
+	}
+
+	if cmdFlags.DryRun {
+		for i, m := range mappings {
+			printContractMappingPlanStep(r.chain, m, existing[i])
+		}
+		return nil
+	}
+
+	// Buffer enough events to cover all the mappings that'll be in flight at once
+	contractMappingConfirmedCh := make(chan *tgtypes.TransferGatewayContractMappingConfirmed, len(mappings))
+	contractMappingSub, err := loomGateway.WatchContractMappingConfirmed(contractMappingConfirmedCh)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to DAppChain events")
+	}
+	defer contractMappingSub.Close()
+
+	results := make([]*contractMappingResult, len(mappings))
+	pending := map[string]*contractMappingResult{}
+	for i, m := range mappings {
+		results[i] = &contractMappingResult{mapping: m, submittedAt: time.Now()}
+		if existing[i] == mappingStatusConfirmed {
+			results[i].status = mappingStatusExisting
+			continue
+		}
+		if existing[i] == mappingStatusPending {
+			// Already submitted by a previous run, just wait for the Oracle to confirm it
+			results[i].status = mappingStatusPending
+			pending[contractMappingKey(m.foreignAddr.Bytes(), m.localAddr.Local)] = results[i]
+			continue
+		}
+		if err := addMapping(m); err != nil {
+			results[i].status = mappingStatusFailed
+			results[i].err = err
+			results[i].elapsed = time.Since(results[i].submittedAt)
+			continue
+		}
+		if m.Authorized {
+			// Authorized mappings take effect immediately
+			results[i].status = mappingStatusConfirmed
+			results[i].elapsed = time.Since(results[i].submittedAt)
+			continue
+		}
+		results[i].status = mappingStatusPending
+		pending[contractMappingKey(m.foreignAddr.Bytes(), m.localAddr.Local)] = results[i]
+	}
+
+	// Let the Oracle fetch pending contract mappings and confirm them
+	timeout := time.After(time.Duration(mapContractsTimeout) * time.Second)
+	for len(pending) > 0 {
+		select {
+		case ev := <-contractMappingConfirmedCh:
+			if ev.ForeignContract == nil || ev.LocalContract == nil {
+				continue
+			}
+			key := contractMappingKey(
+				loom.UnmarshalAddressPB(ev.ForeignContract).Local,
+				loom.UnmarshalAddressPB(ev.LocalContract).Local,
+			)
+			if result, ok := pending[key]; ok {
+				result.status = mappingStatusConfirmed
+				result.elapsed = time.Since(result.submittedAt)
+				delete(pending, key)
+			}
+		case <-timeout:
+			for key, result := range pending {
+				result.status = mappingStatusTimedOut
+				result.elapsed = time.Since(result.submittedAt)
+				delete(pending, key)
+			}
+		}
+	}
+
+	printContractMappingResults(results)
+	for _, result := range results {
+		recordContractMapping(r.chain, result)
+	}
+
+	var unmapped []string
+	for _, result := range results {
+		if result.status != mappingStatusConfirmed && result.status != mappingStatusExisting {
+			unmapped = append(unmapped, result.mapping.Name)
+		}
+	}
+	if len(unmapped) > 0 {
+		return errors.Errorf("failed to map %s contracts", strings.Join(unmapped, ", "))
+	}
+	return nil
+}
+
+func printContractMappingPlanStep(chain string, m *resolvedContractMapping, existing string) {
+	local := m.LocalAddrKey
+	if local == "" {
+		local = "registered as " + m.LocalContract
+	}
+	foreign := m.ForeignAddrKey
+	switch {
+	case m.ForeignAddr != "":
+		foreign = "verbatim"
+	case m.ForeignSymbol != "":
+		foreign = "symbol " + m.ForeignSymbol
+	case m.ForeignSymbolKey != "":
+		foreign = "symbol " + m.ForeignSymbolKey
+	}
+	pair := fmt.Sprintf("%s mapping %s: %s (%s) <==> %s %s (%s)",
+		m.Kind, m.Name, m.localAddr.String(), local, chain, m.foreignAddr.Hex(), foreign)
+
+	switch existing {
+	case mappingStatusConfirmed:
+		printPlanStep("skip %s, already mapped", pair)
+	case mappingStatusPending:
+		printPlanStep("wait for Oracle to confirm %s, already pending", pair)
+	default:
+		action := "add"
+		if m.Authorized {
+			action = "add authorized"
+		}
+		if m.ForeignTxKey != "" {
+			pair += ", tx hash " + m.ForeignTxKey
+		}
+		printPlanStep("%s %s, signed by %s (%s)", action, pair, m.Creator, m.creator.LoomAddr.String())
+	}
+}
+
+func recordContractMapping(chain string, result *contractMappingResult) {
+	mr := &mappingReport{
+		Name:           result.mapping.Name,
+		Kind:           result.mapping.Kind,
+		LocalAddress:   result.mapping.localAddr.String(),
+		ForeignChain:   chain,
+		ForeignAddress: result.mapping.foreignAddr.Hex(),
+		Status:         result.status,
+		DurationMs:     result.elapsed.Nanoseconds() / int64(time.Millisecond),
+	}
+	if result.err != nil {
+		mr.Error = result.err.Error()
+	}
+	report.Mappings = append(report.Mappings, mr)
+}
+
+func printContractMappingResults(results []*contractMappingResult) {
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTRACT\tDAPPCHAIN\tFOREIGN\tSTATUS")
+	for _, r := range results {
+		status := r.status
+		if r.err != nil {
+			status = fmt.Sprintf("%s: %v", status, r.err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			r.mapping.Name, r.mapping.localAddr.String(), r.mapping.foreignAddr.Hex(), status)
+	}
+	w.Flush()
+}
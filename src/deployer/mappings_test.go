@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadContractMappingManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contract-mappings")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		manifest string
+		// Substring of the expected error, empty if the manifest is valid
+		wantErr string
+	}{
+		{
+			name: "valid ethereum mapping",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_addr_key: mainnet_game_token_addr
+    foreign_tx_key: mainnet_game_token_tx
+`,
+		},
+		{
+			name: "valid binance mapping",
+			manifest: `
+binance:
+  - name: BEP2Token
+    kind: bep2
+    creator: alice
+    local_contract: SampleBEP2Token
+    foreign_symbol_key: binance_bep2_token_symbol
+`,
+		},
+		{
+			name: "both local_addr_key and local_contract",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    local_contract: SampleERC20Token
+    foreign_addr_key: mainnet_game_token_addr
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "exactly one of local_addr_key or local_contract",
+		},
+		{
+			name: "neither local_addr_key nor local_contract",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    foreign_addr_key: mainnet_game_token_addr
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "exactly one of local_addr_key or local_contract",
+		},
+		{
+			name: "both foreign_addr_key and foreign_addr",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_addr_key: mainnet_game_token_addr
+    foreign_addr: "0x1111111111111111111111111111111111111111"
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "exactly one of foreign_addr_key, foreign_addr",
+		},
+		{
+			name: "both foreign_addr_key and foreign_symbol",
+			manifest: `
+binance:
+  - name: BEP2Token
+    kind: bep2
+    creator: alice
+    local_contract: SampleBEP2Token
+    foreign_addr_key: binance_bep2_token_addr
+    foreign_symbol: MOOL-1A2
+`,
+			wantErr: "exactly one of foreign_addr_key, foreign_addr",
+		},
+		{
+			name: "both foreign_symbol and foreign_symbol_key",
+			manifest: `
+binance:
+  - name: BEP2Token
+    kind: bep2
+    creator: alice
+    local_contract: SampleBEP2Token
+    foreign_symbol: MOOL-1A2
+    foreign_symbol_key: binance_bep2_token_symbol
+`,
+			wantErr: "exactly one of foreign_addr_key, foreign_addr",
+		},
+		{
+			name: "no foreign address",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "exactly one of foreign_addr_key, foreign_addr",
+		},
+		{
+			name: "foreign_symbol outside binance",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_symbol: GTK
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "foreign_symbol is only supported by Binance mappings",
+		},
+		{
+			name: "invalid foreign_addr",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_addr: "0x1234"
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "invalid foreign_addr",
+		},
+		{
+			name: "missing foreign_tx_key",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_addr_key: mainnet_game_token_addr
+`,
+			wantErr: "missing foreign_tx_key",
+		},
+		{
+			name: "kind of another chain",
+			manifest: `
+tron:
+  - name: GameToken
+    kind: erc20
+    creator: alice
+    local_contract: SampleERC20Token
+    foreign_addr_key: tron_game_token_addr
+`,
+			wantErr: "invalid kind",
+		},
+		{
+			name: "missing creator",
+			manifest: `
+ethereum:
+  - name: GameToken
+    kind: erc20
+    local_addr_key: loomchain_SampleERC20Token_1
+    foreign_addr_key: mainnet_game_token_addr
+    foreign_tx_key: mainnet_game_token_tx
+`,
+			wantErr: "missing creator",
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(dir, fmt.Sprintf("contract_mappings_%d.yml", i))
+			require.NoError(t, ioutil.WriteFile(filename, []byte(test.manifest), 0644))
+			manifest, err := loadContractMappingManifest(filename)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			// Each of the valid manifests has a single mapping
+			require.Equal(t, 1, len(manifest.Ethereum)+len(manifest.Tron)+len(manifest.Binance))
+		})
+	}
+}
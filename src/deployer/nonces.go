@@ -0,0 +1,224 @@
+package main
+
+import (
+	"ethcontract"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type scanNoncesFlags struct {
+	GatewayAddress string
+	FromBlock      uint64
+	ToBlock        uint64
+	BlockRange     uint64
+}
+
+var scanNoncesCmdFlags scanNoncesFlags
+
+func newScanNoncesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan-nonces",
+		Short: "Lists the withdrawal nonces of every account that used the Ethereum Gateway",
+		Long: "Scans the deposit & withdrawal events emitted by the Ethereum Gateway, and reads the " +
+			"withdrawal nonce of every depositor & withdrawer found in the events.",
+		RunE: scanNonces,
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&scanNoncesCmdFlags.GatewayAddress, "gateway", "",
+		"Address of the Ethereum Gateway, defaults to mainnet_gateway_addr in the deployment file")
+	flags.Uint64Var(&scanNoncesCmdFlags.FromBlock, "from-block", 0, "Block to start scanning from")
+	flags.Uint64Var(&scanNoncesCmdFlags.ToBlock, "to-block", 0, "Last block to scan, defaults to the latest block")
+	flags.Uint64Var(&scanNoncesCmdFlags.BlockRange, "block-range", 10000,
+		"Max number of blocks to fetch events for in one request, 0 to fetch all events in one request")
+	return cmd
+}
+
+type nonceReport struct {
+	Address     string `json:"address"`
+	Nonce       uint64 `json:"nonce"`
+	Withdrawals int    `json:"withdrawals"`
+	Deposits    int    `json:"deposits"`
+	LastBlock   uint64 `json:"last_block"`
+}
+
+// nonceScanner accumulates the accounts found in the Ethereum Gateway events.
+type nonceScanner struct {
+	gateway  *ethcontract.MainnetGatewayContract
+	accounts map[common.Address]*nonceReport
+}
+
+func (s *nonceScanner) account(addr common.Address, block uint64) *nonceReport {
+	account, ok := s.accounts[addr]
+	if !ok {
+		account = &nonceReport{Address: addr.Hex()}
+		s.accounts[addr] = account
+	}
+	if block > account.LastBlock {
+		account.LastBlock = block
+	}
+	return account
+}
+
+func (s *nonceScanner) withdrawal(addr common.Address, block uint64) {
+	s.account(addr, block).Withdrawals++
+}
+
+func (s *nonceScanner) deposit(addr common.Address, block uint64) {
+	s.account(addr, block).Deposits++
+}
+
+// Records the depositors & withdrawers found in the events emitted within the given block range.
+func (s *nonceScanner) scan(opts *bind.FilterOpts) error {
+	withdrawals, err := s.gateway.FilterTokenWithdrawn(opts, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch withdrawals")
+	}
+	defer withdrawals.Close()
+	for withdrawals.Next() {
+		s.withdrawal(withdrawals.Event.Owner, withdrawals.Event.Raw.BlockNumber)
+	}
+	if err := withdrawals.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch withdrawals")
+	}
+
+	ethDeposits, err := s.gateway.FilterETHReceived(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch ETH deposits")
+	}
+	defer ethDeposits.Close()
+	for ethDeposits.Next() {
+		s.deposit(ethDeposits.Event.From, ethDeposits.Event.Raw.BlockNumber)
+	}
+	if err := ethDeposits.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch ETH deposits")
+	}
+
+	loomDeposits, err := s.gateway.FilterLoomCoinReceived(opts, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch LOOM deposits")
+	}
+	defer loomDeposits.Close()
+	for loomDeposits.Next() {
+		s.deposit(loomDeposits.Event.From, loomDeposits.Event.Raw.BlockNumber)
+	}
+	if err := loomDeposits.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch LOOM deposits")
+	}
+
+	erc20Deposits, err := s.gateway.FilterERC20Received(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch ERC20 deposits")
+	}
+	defer erc20Deposits.Close()
+	for erc20Deposits.Next() {
+		s.deposit(erc20Deposits.Event.From, erc20Deposits.Event.Raw.BlockNumber)
+	}
+	if err := erc20Deposits.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch ERC20 deposits")
+	}
+
+	erc721Deposits, err := s.gateway.FilterERC721Received(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch ERC721 deposits")
+	}
+	defer erc721Deposits.Close()
+	for erc721Deposits.Next() {
+		s.deposit(erc721Deposits.Event.From, erc721Deposits.Event.Raw.BlockNumber)
+	}
+	if err := erc721Deposits.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch ERC721 deposits")
+	}
+
+	erc721xDeposits, err := s.gateway.FilterERC721XReceived(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch ERC721X deposits")
+	}
+	defer erc721xDeposits.Close()
+	for erc721xDeposits.Next() {
+		s.deposit(erc721xDeposits.Event.From, erc721xDeposits.Event.Raw.BlockNumber)
+	}
+	if err := erc721xDeposits.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch ERC721X deposits")
+	}
+
+	// The To field of ERC721XBatchReceived is the account the batch was received from.
+	erc721xBatchDeposits, err := s.gateway.FilterERC721XBatchReceived(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch ERC721X batch deposits")
+	}
+	defer erc721xBatchDeposits.Close()
+	for erc721xBatchDeposits.Next() {
+		s.deposit(erc721xBatchDeposits.Event.To, erc721xBatchDeposits.Event.Raw.BlockNumber)
+	}
+	if err := erc721xBatchDeposits.Error(); err != nil {
+		return errors.Wrap(err, "failed to fetch ERC721X batch deposits")
+	}
+	return nil
+}
+
+func scanNonces(cmd *cobra.Command, args []string) error {
+	flags := scanNoncesCmdFlags
+	gatewayAddr, err := resolveEthereumGatewayAddress(flags.GatewayAddress)
+	if err != nil {
+		return err
+	}
+	ethClient, err := dialEthereum()
+	if err != nil {
+		return err
+	}
+	defer ethClient.Close()
+
+	gw, err := ethcontract.NewMainnetGatewayContract(gatewayAddr, ethClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Ethereum Gateway")
+	}
+
+	toBlock := flags.ToBlock
+	if toBlock == 0 {
+		if toBlock, err = latestBlockNumber(ethClient); err != nil {
+			return err
+		}
+	}
+	if flags.FromBlock > toBlock {
+		return errors.Errorf("--from-block %d is after --to-block %d", flags.FromBlock, toBlock)
+	}
+
+	scanner := &nonceScanner{
+		gateway:  gw,
+		accounts: map[common.Address]*nonceReport{},
+	}
+	if err := scanBlockRanges(flags.FromBlock, toBlock, flags.BlockRange, scanner.scan); err != nil {
+		return err
+	}
+
+	addrs := make([]common.Address, 0, len(scanner.accounts))
+	for addr := range scanner.accounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Hex() < addrs[j].Hex()
+	})
+
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tNONCE\tWITHDRAWALS\tDEPOSITS\tLAST BLOCK")
+	for _, addr := range addrs {
+		account := scanner.accounts[addr]
+		nonce, err := gw.Nonces(nil, addr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch nonce of %s", account.Address)
+		}
+		account.Nonce = nonce.Uint64()
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n",
+			account.Address, account.Nonce, account.Withdrawals, account.Deposits, account.LastBlock)
+		report.Nonces = append(report.Nonces, account)
+	}
+	w.Flush()
+	fmt.Fprintf(textOut, "found %d accounts in blocks %d-%d\n", len(addrs), flags.FromBlock, toBlock)
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"gateway"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Supported --output formats.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// Human readable output, sent to stderr in JSON mode so that stdout only contains the report.
+var textOut io.Writer = os.Stdout
+
+// commandReport is the result document a command emits in JSON mode.
+type commandReport struct {
+	Command           string                      `json:"command"`
+	DryRun            bool                        `json:"dry_run"`
+	Success           bool                        `json:"success"`
+	Error             string                      `json:"error,omitempty"`
+	StartedAt         time.Time                   `json:"started_at"`
+	DurationMs        int64                       `json:"duration_ms"`
+	Plan              []string                    `json:"plan,omitempty"`
+	Deployments       []*deploymentReport         `json:"deployments,omitempty"`
+	Mappings          []*mappingReport            `json:"mappings,omitempty"`
+	Tokens            []*tokenReport              `json:"tokens,omitempty"`
+	Transactions      []*txReport                 `json:"transactions,omitempty"`
+	FilesWritten      []string                    `json:"files_written,omitempty"`
+	Status            []*contractStatus           `json:"status,omitempty"`
+	Nonces            []*nonceReport              `json:"nonces,omitempty"`
+	Holdings          []*tokenHolding             `json:"holdings,omitempty"`
+	Reconciliation    []*reconciliationReport     `json:"reconciliation,omitempty"`
+	ValidatorSet      *validatorSetReport         `json:"validator_set,omitempty"`
+	ValidatorCheck    *validatorCheckReport       `json:"validator_check,omitempty"`
+	ValidatorHistory  []*validatorSetReport       `json:"validator_history,omitempty"`
+	Withdrawal        *gateway.WithdrawalProgress `json:"withdrawal,omitempty"`
+	PendingWithdrawal *pendingWithdrawalReport    `json:"pending_withdrawal,omitempty"`
+	Deposit           *depositReport              `json:"deposit,omitempty"`
+}
+
+type deploymentReport struct {
+	Name       string `json:"name"`
+	Chain      string `json:"chain"`
+	Address    string `json:"address"`
+	TxHash     string `json:"tx_hash,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type mappingReport struct {
+	Name           string `json:"name"`
+	Kind           string `json:"kind"`
+	LocalAddress   string `json:"local_address"`
+	ForeignChain   string `json:"foreign_chain"`
+	ForeignAddress string `json:"foreign_address"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	DurationMs     int64  `json:"duration_ms"`
+}
+
+type tokenReport struct {
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	Supply int64  `json:"supply"`
+	TxHash string `json:"tx_hash"`
+}
+
+type txReport struct {
+	Name       string `json:"name"`
+	TxHash     string `json:"tx_hash"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+var report = &commandReport{}
+
+func startReport(cmd *cobra.Command, args []string) error {
+	switch cmdFlags.Output {
+	case outputText:
+	case outputJSON:
+		textOut = os.Stderr
+	default:
+		return errors.Errorf("invalid output format %s, expected %s or %s", cmdFlags.Output, outputText, outputJSON)
+	}
+	report.Command = cmd.Name()
+	report.DryRun = cmdFlags.DryRun
+	report.StartedAt = time.Now()
+	return nil
+}
+
+// Writes the JSON report for the command that just finished running to stdout, does nothing unless
+// JSON output was requested.
+func finishReport(cmdErr error) error {
+	if cmdFlags.Output != outputJSON {
+		return nil
+	}
+	report.Success = cmdErr == nil
+	if cmdErr != nil {
+		report.Error = cmdErr.Error()
+	}
+	if !report.StartedAt.IsZero() {
+		report.DurationMs = durationMs(report.StartedAt)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func durationMs(start time.Time) int64 {
+	return time.Since(start).Nanoseconds() / int64(time.Millisecond)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"ethcontract"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Args of rotateValidators, shared by rotate-validators & governance propose rotate-validators.
+type rotateValidatorsArgs struct {
+	Validators        []string
+	Powers            []string
+	NewValidatorsFile string
+}
+
+func (a *rotateValidatorsArgs) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVar(&a.Validators, "validators", nil,
+		"Ethereum addresses of the new validators")
+	flags.StringSliceVar(&a.Powers, "powers", nil,
+		"Powers of the new validators, in the same order as --validators")
+	flags.StringVar(&a.NewValidatorsFile, "new-validators-file", "",
+		"JSON file containing the addresses & powers of the new validators (same format as newValidators.json)")
+}
+
+func (a *rotateValidatorsArgs) action() (*vmcAction, error) {
+	newValidators, newPowers, err := a.newValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	return newRotateValidatorsAction(newValidators, newPowers), nil
+}
+
+type rotateValidatorsFlags struct {
+	validatorSignerFlags
+	rotateValidatorsArgs
+}
+
+var rotateValidatorsCmdFlags rotateValidatorsFlags
+
+func newRotateValidatorsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-validators",
+		Short: "Replaces the validator set of the Validator Manager Contract on Ethereum",
+		RunE:  rotateValidators,
+	}
+	rotateValidatorsCmdFlags.validatorSignerFlags.register(cmd)
+	rotateValidatorsCmdFlags.rotateValidatorsArgs.register(cmd)
+	return cmd
+}
+
+func rotateValidators(cmd *cobra.Command, args []string) error {
+	flags := rotateValidatorsCmdFlags
+	action, err := flags.action()
+	if err != nil {
+		return err
+	}
+	return executeVMCAction(&flags.validatorSignerFlags, action)
+}
+
+func newRotateValidatorsAction(newValidators []common.Address, newPowers []uint64) *vmcAction {
+	var validators []string
+	for i, validator := range newValidators {
+		validators = append(validators, fmt.Sprintf("%s (%d)", validator.Hex(), newPowers[i]))
+	}
+	return &vmcAction{
+		name:        "rotateValidators",
+		description: "rotate validators to " + strings.Join(validators, ", "),
+		argsHash:    hashValidatorSet(newValidators, newPowers),
+		submit: func(vmc *ethcontract.ValidatorManagerContract, opts *bind.TransactOpts, sigs *validatorSignatures) (*types.Transaction, error) {
+			return vmc.RotateValidators(opts, newValidators, newPowers, sigs.SignersIndexes, sigs.V, sigs.R, sigs.S)
+		},
+	}
+}
+
+// Returns the new validator set specified via --validators & --powers, or --new-validators-file.
+func (f *rotateValidatorsArgs) newValidatorSet() ([]common.Address, []uint64, error) {
+	addrs, powers := f.Validators, f.Powers
+	if f.NewValidatorsFile != "" {
+		if len(addrs) > 0 || len(powers) > 0 {
+			return nil, nil, errors.New("--new-validators-file can't be combined with --validators or --powers")
+		}
+		data, err := ioutil.ReadFile(f.NewValidatorsFile)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read new validators file")
+		}
+		var newValidators struct {
+			Addresses []string `json:"addresses"`
+			Powers    []uint64 `json:"powers"`
+		}
+		if err := json.Unmarshal(data, &newValidators); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse %s", f.NewValidatorsFile)
+		}
+		addrs = newValidators.Addresses
+		for _, power := range newValidators.Powers {
+			powers = append(powers, strconv.FormatUint(power, 10))
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, nil, errors.New("no validators specified, use --validators & --powers, or --new-validators-file")
+	}
+	if len(addrs) != len(powers) {
+		return nil, nil, errors.Errorf("got %d validators but %d powers", len(addrs), len(powers))
+	}
+
+	validators := make([]common.Address, len(addrs))
+	validatorPowers := make([]uint64, len(powers))
+	for i := range addrs {
+		if !common.IsHexAddress(addrs[i]) {
+			return nil, nil, errors.Errorf("invalid validator address %s", addrs[i])
+		}
+		validators[i] = common.HexToAddress(addrs[i])
+		power, err := strconv.ParseUint(powers[i], 10, 64)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid power for validator %s", addrs[i])
+		}
+		validatorPowers[i] = power
+	}
+	return validators, validatorPowers, nil
+}
+
+// Computes keccak256(abi.encodePacked(validators, powers)), array elements are padded to 32 bytes
+// by abi.encodePacked.
+func hashValidatorSet(validators []common.Address, powers []uint64) []byte {
+	var data [][]byte
+	for _, validator := range validators {
+		data = append(data, common.LeftPadBytes(validator.Bytes(), 32))
+	}
+	for _, power := range powers {
+		data = append(data, common.LeftPadBytes(new(big.Int).SetUint64(power).Bytes(), 32))
+	}
+	return crypto.Keccak256(data...)
+}
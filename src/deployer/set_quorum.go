@@ -0,0 +1,128 @@
+package main
+
+import (
+	"ethcontract"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Args of setQuorum, shared by set-quorum & governance propose set-quorum.
+type setQuorumArgs struct {
+	Num   uint8
+	Denom uint8
+}
+
+func (a *setQuorumArgs) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.Uint8Var(&a.Num, "num", 0, "Numerator of the new threshold fraction")
+	flags.Uint8Var(&a.Denom, "denom", 0, "Denominator of the new threshold fraction")
+	cmd.MarkFlagRequired("num")
+	cmd.MarkFlagRequired("denom")
+}
+
+func (a *setQuorumArgs) action() (*vmcAction, error) {
+	return newSetQuorumAction(a.Num, a.Denom)
+}
+
+type setQuorumFlags struct {
+	validatorSignerFlags
+	setQuorumArgs
+}
+
+var setQuorumCmdFlags setQuorumFlags
+
+func newSetQuorumCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-quorum",
+		Short: "Changes the fraction of validator power required to approve changes on Ethereum",
+		RunE:  setQuorum,
+	}
+	setQuorumCmdFlags.validatorSignerFlags.register(cmd)
+	setQuorumCmdFlags.setQuorumArgs.register(cmd)
+	return cmd
+}
+
+func setQuorum(cmd *cobra.Command, args []string) error {
+	flags := setQuorumCmdFlags
+	action, err := flags.action()
+	if err != nil {
+		return err
+	}
+	return executeVMCAction(&flags.validatorSignerFlags, action)
+}
+
+func newSetQuorumAction(num, denom uint8) (*vmcAction, error) {
+	// Same check as setQuorum in ValidatorManagerContract.sol
+	if num == 0 || num > denom {
+		return nil, errors.Errorf("invalid threshold fraction %d/%d", num, denom)
+	}
+	return &vmcAction{
+		name:        "setQuorum",
+		description: fmt.Sprintf("set threshold to %d/%d", num, denom),
+		argsHash:    crypto.Keccak256([]byte{num, denom}),
+		submit: func(vmc *ethcontract.ValidatorManagerContract, opts *bind.TransactOpts, sigs *validatorSignatures) (*types.Transaction, error) {
+			return vmc.SetQuorum(opts, num, denom, sigs.SignersIndexes, sigs.V, sigs.R, sigs.S)
+		},
+	}, nil
+}
+
+// Args of setLoom, shared by set-loom & governance propose set-loom.
+type setLoomArgs struct {
+	LoomAddress string
+}
+
+func (a *setLoomArgs) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&a.LoomAddress, "loom-token", "", "Ethereum address of the new LOOM token contract")
+	cmd.MarkFlagRequired("loom-token")
+}
+
+func (a *setLoomArgs) action() (*vmcAction, error) {
+	if !common.IsHexAddress(a.LoomAddress) {
+		return nil, errors.Errorf("invalid LOOM token address %s", a.LoomAddress)
+	}
+	return newSetLoomAction(common.HexToAddress(a.LoomAddress)), nil
+}
+
+type setLoomFlags struct {
+	validatorSignerFlags
+	setLoomArgs
+}
+
+var setLoomCmdFlags setLoomFlags
+
+func newSetLoomCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-loom",
+		Short: "Changes the LOOM token address stored in the Validator Manager Contract on Ethereum",
+		RunE:  setLoom,
+	}
+	setLoomCmdFlags.validatorSignerFlags.register(cmd)
+	setLoomCmdFlags.setLoomArgs.register(cmd)
+	return cmd
+}
+
+func setLoom(cmd *cobra.Command, args []string) error {
+	flags := setLoomCmdFlags
+	action, err := flags.action()
+	if err != nil {
+		return err
+	}
+	return executeVMCAction(&flags.validatorSignerFlags, action)
+}
+
+func newSetLoomAction(loomAddr common.Address) *vmcAction {
+	return &vmcAction{
+		name:        "setLoom",
+		description: "set LOOM token address to " + loomAddr.Hex(),
+		argsHash:    crypto.Keccak256(loomAddr.Bytes()),
+		submit: func(vmc *ethcontract.ValidatorManagerContract, opts *bind.TransactOpts, sigs *validatorSignatures) (*types.Transaction, error) {
+			return vmc.SetLoom(opts, loomAddr, sigs.SignersIndexes, sigs.V, sigs.R, sigs.S)
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetQuorumAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		num      uint8
+		denom    uint8
+		wantHash string
+		wantErr  bool
+	}{
+		{name: "two thirds", num: 2, denom: 3, wantHash: "57ca2fe04d5cba0d4d4219560d4b2e77c3f4f8c7214a1b99ee8c3a7fa01184fe"},
+		{name: "zero numerator", num: 0, denom: 3, wantErr: true},
+		{name: "numerator above denominator", num: 4, denom: 3, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			action, err := newSetQuorumAction(test.num, test.denom)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantHash, hex.EncodeToString(action.argsHash))
+		})
+	}
+}
+
+func TestSetQuorumMessage(t *testing.T) {
+	action, err := newSetQuorumAction(2, 3)
+	require.NoError(t, err)
+	set := &validatorSet{Address: testVMCAddr, Nonce: big.NewInt(7)}
+	require.Equal(t,
+		"1fe2d1781596643fcaf62b096a5f88e6293ce86a15009545bb8605f294a6cf95",
+		hex.EncodeToString(set.createMessage(action.argsHash)),
+	)
+}
+
+func TestSetLoomAction(t *testing.T) {
+	action := newSetLoomAction(common.HexToAddress("0x4444444444444444444444444444444444444444"))
+	require.Equal(t,
+		"4cfa6af4bfa0111fd5e7625d43e84cd2d40629cf6008219d2c0e30ed48abf8b6",
+		hex.EncodeToString(action.argsHash),
+	)
+}
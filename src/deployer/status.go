@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateway"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	loom_client "github.com/loomnetwork/go-loom/client"
+	gw "github.com/loomnetwork/go-loom/client/gateway"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var statusChain string
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Checks the test contracts & contract mappings listed in the deployment file",
+		RunE:  status,
+	}
+	cmd.Flags().StringVar(&statusChain, "gateway", ethereumChain,
+		"Foreign chain whose DAppChain Gateway should be checked: ethereum, tron, or binance")
+	cmd.Flags().StringVar(&mappingManifestPath, "manifest", "",
+		"YAML or JSON file listing the contract mappings, e.g. e2e_config/contract_mappings.yml")
+	cmd.MarkFlagRequired("manifest")
+	return cmd
+}
+
+// contractStatus is the result of checking a single contract & its mapping.
+type contractStatus struct {
+	Name           string `json:"name"`
+	Kind           string `json:"kind"`
+	LocalAddress   string `json:"local_address,omitempty"`
+	ForeignAddress string `json:"foreign_address,omitempty"`
+	DAppChainCode  string `json:"dappchain_code"`
+	ForeignCode    string `json:"foreign_code"`
+	Mapping        string `json:"mapping"`
+	OK             bool   `json:"ok"`
+	Problems       string `json:"problems,omitempty"`
+}
+
+// Values of the code & mapping columns in the status table.
+const (
+	statusOK           = "ok"
+	statusMissing      = "missing"
+	statusConflict     = "conflict"
+	statusNotChecked   = "-"
+	statusNotAvailable = "n/a"
+)
+
+func status(cmd *cobra.Command, args []string) error {
+	contractNames := map[string]bool{}
+	for _, contractName := range cmdFlags.DAppChainContractNames {
+		contractNames[contractName] = true
+	}
+
+	loomCfg, err := gateway.ParseConfig([]string{cmdFlags.LoomDir})
+	if err != nil {
+		return errors.Wrap(err, "failed to parse loom config")
+	}
+
+	manifest, err := loadContractMappingManifest(mappingManifestPath)
+	if err != nil {
+		return err
+	}
+
+	deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to load deployment info file")
+	}
+
+	loomClient := loom_client.NewDAppChainRPCClient(
+		loomCfg.ChainID,
+		loomCfg.TransferGateway.DAppChainWriteURI,
+		loomCfg.TransferGateway.DAppChainReadURI,
+	)
+
+	var loomGateway *gw.DAppChainGateway
+	switch statusChain {
+	case ethereumChain:
+		loomGateway, err = gw.ConnectToDAppChainGateway(loomClient, loomCfg.TransferGateway.DAppChainEventsURI)
+	case tronChain:
+		loomGateway, err = gw.ConnectToDAppChainTronGateway(loomClient, loomCfg.TransferGateway.DAppChainEventsURI)
+	case binanceChain:
+		loomGateway, err = gw.ConnectToDAppChainBinanceGateway(loomClient, loomCfg.TransferGateway.DAppChainEventsURI)
+	default:
+		return errors.Errorf("invalid gateway %s, expected ethereum, tron, or binance", statusChain)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Gateway on DAppChain")
+	}
+
+	var statuses []*contractStatus
+
+	// Only Ethereum contracts can be checked directly, Tron & Binance contracts are assumed to exist
+	var ethClient *ethclient.Client
+	if statusChain == ethereumChain {
+		ethClient, err = ethclient.Dial(loomCfg.TransferGateway.EthereumURI)
+		if err != nil {
+			return errors.Wrap(err, "failed to connect to Ethereum")
+		}
+		defer ethClient.Close()
+
+		st := &contractStatus{
+			Name:          "MainnetGateway",
+			Kind:          statusNotAvailable,
+			DAppChainCode: statusNotAvailable,
+			Mapping:       statusNotAvailable,
+		}
+		st.ForeignAddress = deploymentInfo.MainnetGatewayAddr
+		if common.IsHexAddress(st.ForeignAddress) {
+			st.ForeignCode, err = checkEthereumCode(ethClient, common.HexToAddress(st.ForeignAddress))
+			if err != nil {
+				st.Problems = err.Error()
+			}
+		} else {
+			st.ForeignCode = statusMissing
+			st.Problems = "missing mainnet_gateway_addr"
+		}
+		st.OK = st.ForeignCode == statusOK
+		statuses = append(statuses, st)
+	}
+
+	resolver := &contractMappingResolver{
+		chain:          statusChain,
+		chainID:        loomCfg.ChainID,
+		loomClient:     loomClient,
+		deploymentInfo: deploymentInfo,
+		createIdentity: newTestAccountIdentityFunc(statusChain, loomCfg.ChainID),
+	}
+
+	mappings := manifest.Chain(statusChain)
+	for i := range mappings {
+		m := &mappings[i]
+		if len(contractNames) > 0 && !contractNames[m.Name] {
+			continue
+		}
+
+		st := &contractStatus{
+			Name:          m.Name,
+			Kind:          m.Kind,
+			DAppChainCode: statusNotChecked,
+			ForeignCode:   statusNotChecked,
+			Mapping:       statusNotChecked,
+		}
+		statuses = append(statuses, st)
+
+		rm, err := resolver.resolve(m)
+		if err != nil {
+			st.Problems = err.Error()
+			continue
+		}
+		st.LocalAddress = rm.localAddr.String()
+		st.ForeignAddress = rm.foreignAddr.Hex()
+
+		var problems []string
+
+		code, err := loomClient.GetEvmCode(rm.localAddr.String())
+		switch {
+		case err != nil:
+			problems = append(problems, errors.Wrap(err, "failed to fetch DAppChain contract code").Error())
+		case len(code) == 0:
+			st.DAppChainCode = statusMissing
+		default:
+			st.DAppChainCode = statusOK
+		}
+
+		// Only contracts deployed by the tests have code, native coins are mapped to fake addresses
+		if ethClient != nil && m.ForeignAddrKey != "" {
+			st.ForeignCode, err = checkEthereumCode(ethClient, rm.foreignAddr)
+			if err != nil {
+				problems = append(problems, err.Error())
+			}
+		} else {
+			st.ForeignCode = statusNotAvailable
+		}
+
+		existing, err := resolver.findExistingMapping(loomGateway.Address, rm)
+		switch {
+		case err != nil:
+			st.Mapping = statusConflict
+			problems = append(problems, err.Error())
+		case existing == "":
+			st.Mapping = statusMissing
+		default:
+			st.Mapping = existing
+		}
+
+		st.Problems = strings.Join(problems, "; ")
+		st.OK = st.DAppChainCode == statusOK &&
+			(st.ForeignCode == statusOK || st.ForeignCode == statusNotAvailable) &&
+			st.Mapping == mappingStatusConfirmed
+	}
+
+	printContractStatuses(statuses)
+	report.Status = statuses
+
+	unhealthy := 0
+	for _, st := range statuses {
+		if !st.OK {
+			unhealthy++
+		}
+	}
+	if unhealthy > 0 {
+		return errors.Errorf("%d of %d contracts are missing or inconsistent", unhealthy, len(statuses))
+	}
+	return nil
+}
+
+// Returns statusOK if there's code at the given Ethereum address, statusMissing otherwise.
+func checkEthereumCode(ethClient *ethclient.Client, addr common.Address) (string, error) {
+	code, err := ethClient.CodeAt(context.TODO(), addr, nil)
+	if err != nil {
+		return statusNotChecked, errors.Wrap(err, "failed to fetch Ethereum contract code")
+	}
+	if len(code) == 0 {
+		return statusMissing, nil
+	}
+	return statusOK, nil
+}
+
+func printContractStatuses(statuses []*contractStatus) {
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTRACT\tKIND\tDAPPCHAIN\tFOREIGN\tDAPPCHAIN CODE\tFOREIGN CODE\tMAPPING\tOK")
+	for _, st := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%v\n",
+			st.Name, st.Kind, st.LocalAddress, st.ForeignAddress,
+			st.DAppChainCode, st.ForeignCode, st.Mapping, st.OK,
+		)
+	}
+	w.Flush()
+
+	for _, st := range statuses {
+		if st.Problems != "" {
+			fmt.Fprintf(textOut, "%s: %s\n", st.Name, st.Problems)
+		}
+	}
+}
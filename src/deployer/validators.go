@@ -0,0 +1,401 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"ethcontract"
+	"fmt"
+	"gateway"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Flags shared by the commands that need signatures from the current validators.
+type validatorSignerFlags struct {
+	VMCAddress     string
+	SignerKeyFiles []string
+	SignersFile    string
+	ethereumSenderFlags
+}
+
+func (f *validatorSignerFlags) register(cmd *cobra.Command) {
+	f.registerVMCFlags(cmd)
+	f.registerSignerFlags(cmd)
+	f.registerSenderFlags(cmd)
+}
+
+func (f *validatorSignerFlags) registerVMCFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.VMCAddress, "vmc", "",
+		"Address of the Validator Manager Contract, defaults to mainnet_validatorManagerContract_addr in the deployment file")
+}
+
+func (f *validatorSignerFlags) registerSignerFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVar(&f.SignerKeyFiles, "signer-key", nil,
+		"Files containing the hex encoded private keys of the validators that should sign the message")
+	flags.StringVar(&f.SignersFile, "signers-file", "",
+		"JSON file containing the privateKeys of the validators that should sign the message (same format as signers.json)")
+}
+
+// Returns the address of the Validator Manager Contract specified via --vmc, or the one listed in
+// the deployment file.
+func (f *validatorSignerFlags) vmcAddress() (common.Address, error) {
+	addr := f.VMCAddress
+	if addr == "" {
+		deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+		if err != nil {
+			return common.Address{}, errors.Wrap(err, "failed to load deployment info file")
+		}
+		if err := deploymentInfo.Require("mainnet_validatorManagerContract_addr"); err != nil {
+			return common.Address{}, err
+		}
+		addr = deploymentInfo.MainnetVMCAddr
+	}
+	if !common.IsHexAddress(addr) {
+		return common.Address{}, errors.Errorf("invalid Validator Manager Contract address %s", addr)
+	}
+	return common.HexToAddress(addr), nil
+}
+
+// Loads the validator keys specified via --signer-key & --signers-file.
+func (f *validatorSignerFlags) signerKeys() ([]*ecdsa.PrivateKey, error) {
+	var keys []*ecdsa.PrivateKey
+	for _, filename := range f.SignerKeyFiles {
+		key, err := loadEthereumKeyFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if f.SignersFile != "" {
+		data, err := ioutil.ReadFile(f.SignersFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read signers file")
+		}
+		var signers struct {
+			PrivateKeys []string `json:"privateKeys"`
+		}
+		if err := json.Unmarshal(data, &signers); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", f.SignersFile)
+		}
+		for i, hexKey := range signers.PrivateKeys {
+			key, err := parseEthereumKey(hexKey)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid private key #%d in %s", i, f.SignersFile)
+			}
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signer keys specified, use --signer-key or --signers-file")
+	}
+	return keys, nil
+}
+
+// validatorSet is a snapshot of the state of the Validator Manager Contract.
+type validatorSet struct {
+	Address        common.Address
+	Validators     []common.Address
+	Powers         []uint64
+	TotalPower     *big.Int
+	ThresholdNum   uint8
+	ThresholdDenom uint8
+	Nonce          *big.Int
+	LoomAddress    common.Address
+}
+
+func connectToVMC(ethClient *ethclient.Client, addr common.Address) (*ethcontract.ValidatorManagerContract, error) {
+	vmc, err := ethcontract.NewValidatorManagerContract(addr, ethClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to Validator Manager Contract")
+	}
+	return vmc, nil
+}
+
+func loadValidatorSet(vmc *ethcontract.ValidatorManagerContract, addr common.Address) (*validatorSet, error) {
+	set := &validatorSet{Address: addr}
+	var err error
+	if set.Validators, err = vmc.GetValidators(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch validators")
+	}
+	if set.Powers, err = vmc.GetPowers(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch validator powers")
+	}
+	if set.TotalPower, err = vmc.TotalPower(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch total power")
+	}
+	if set.ThresholdNum, err = vmc.ThresholdNum(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch threshold numerator")
+	}
+	if set.ThresholdDenom, err = vmc.ThresholdDenom(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch threshold denominator")
+	}
+	if set.Nonce, err = vmc.Nonce(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch nonce")
+	}
+	if set.LoomAddress, err = vmc.LoomAddress(nil); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch LOOM token address")
+	}
+	return set, nil
+}
+
+// Returns the index of the given validator, or -1 if the address doesn't belong to a validator.
+func (set *validatorSet) indexOf(addr common.Address) int {
+	for i, validator := range set.Validators {
+		if validator == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the hash the validators must sign to approve a change, it's computed the same way as
+// createMessage in ValidatorManagerContract.sol, i.e. keccak256(address(this), nonce, hash).
+func (set *validatorSet) createMessage(hash []byte) []byte {
+	return crypto.Keccak256(
+		set.Address.Bytes(),
+		common.LeftPadBytes(set.Nonce.Bytes(), 32),
+		hash,
+	)
+}
+
+// Checks that the signatures carry enough power to meet the threshold, the same way checkThreshold
+// in ValidatorManagerContract.sol does.
+func (set *validatorSet) checkThreshold(sigs *validatorSignatures) error {
+	votedPower := new(big.Int).Mul(sigs.VotedPower, big.NewInt(int64(set.ThresholdDenom)))
+	requiredPower := new(big.Int).Mul(set.TotalPower, big.NewInt(int64(set.ThresholdNum)))
+	if votedPower.Cmp(requiredPower) < 0 {
+		return errors.Errorf(
+			"signers have %v of %v total power, at least %d/%d is required",
+			sigs.VotedPower, set.TotalPower, set.ThresholdNum, set.ThresholdDenom,
+		)
+	}
+	return nil
+}
+
+func (set *validatorSet) print() {
+	fmt.Fprintf(textOut, "Validator Manager Contract: %s\n", set.Address.Hex())
+	fmt.Fprintf(textOut, "nonce: %v, threshold: %d/%d, total power: %v, LOOM token: %s\n",
+		set.Nonce, set.ThresholdNum, set.ThresholdDenom, set.TotalPower, set.LoomAddress.Hex())
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tVALIDATOR\tPOWER")
+	for i, validator := range set.Validators {
+		fmt.Fprintf(w, "%d\t%s\t%d\n", i, validator.Hex(), set.Powers[i])
+	}
+	w.Flush()
+}
+
+// validatorSignatures holds the arguments checkThreshold expects, with the signatures ordered by
+// the index of the signer in the current validator set.
+type validatorSignatures struct {
+	SignersIndexes []*big.Int
+	V              []uint8
+	R              [][32]byte
+	S              [][32]byte
+	VotedPower     *big.Int
+}
+
+// Returns the hash checkThreshold in ValidatorManagerContract.sol verifies the signatures against.
+func prefixedVMCMessage(message []byte) []byte {
+	return crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), message)
+}
+
+// Signs the message the same way web3.eth.accounts.sign does, V is 27 or 28 in the returned
+// signature.
+func signVMCMessage(message []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := crypto.Sign(prefixedVMCMessage(message), key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// Returns the address of the account that signed the message.
+func recoverVMCMessageSigner(message []byte, sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, errors.Errorf("invalid signature length %d", len(sig))
+	}
+	// crypto.SigToPub expects V to be 0 or 1
+	rsv := make([]byte, 65)
+	copy(rsv, sig)
+	if rsv[64] >= 27 {
+		rsv[64] -= 27
+	}
+	pubKey, err := crypto.SigToPub(prefixedVMCMessage(message), rsv)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// Signs the message with each of the given keys. Keys that don't belong to a current validator
+// are rejected, since the contract would revert anyway.
+func (set *validatorSet) sign(message []byte, keys []*ecdsa.PrivateKey) (*validatorSignatures, error) {
+	var sigs [][]byte
+	for _, key := range keys {
+		sig, err := signVMCMessage(message, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to sign message with key of %s",
+				crypto.PubkeyToAddress(key.PublicKey).Hex())
+		}
+		sigs = append(sigs, sig)
+	}
+	return set.collectSignatures(message, sigs)
+}
+
+// Orders the signatures of the message by the index of the signer in the validator set, as
+// checkThreshold expects. Signatures from accounts that aren't current validators are rejected.
+func (set *validatorSet) collectSignatures(message []byte, sigs [][]byte) (*validatorSignatures, error) {
+	type signature struct {
+		index int
+		sig   []byte
+	}
+	var signatures []signature
+	signed := map[int]bool{}
+	for _, sig := range sigs {
+		signer, err := recoverVMCMessageSigner(message, sig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to recover signer")
+		}
+		index := set.indexOf(signer)
+		if index < 0 {
+			return nil, errors.Errorf("%s is not a current validator", signer.Hex())
+		}
+		// The contract requires strictly increasing indexes, so each validator can only sign once
+		if signed[index] {
+			continue
+		}
+		signatures = append(signatures, signature{index: index, sig: sig})
+		signed[index] = true
+	}
+	sort.Slice(signatures, func(i, j int) bool {
+		return signatures[i].index < signatures[j].index
+	})
+
+	result := &validatorSignatures{VotedPower: new(big.Int)}
+	for _, s := range signatures {
+		var r, sv [32]byte
+		copy(r[:], s.sig[:32])
+		copy(sv[:], s.sig[32:64])
+		result.SignersIndexes = append(result.SignersIndexes, big.NewInt(int64(s.index)))
+		result.V = append(result.V, s.sig[64])
+		result.R = append(result.R, r)
+		result.S = append(result.S, sv)
+		result.VotedPower.Add(result.VotedPower, new(big.Int).SetUint64(set.Powers[s.index]))
+	}
+	return result, nil
+}
+
+// vmcAction is a change to the Validator Manager Contract that must be approved by the validators.
+type vmcAction struct {
+	// Name of the contract method, e.g. rotateValidators
+	name string
+	// Human readable description of the change
+	description string
+	// Hash of the method args that the contract passes to createMessage
+	argsHash []byte
+	submit   func(vmc *ethcontract.ValidatorManagerContract, opts *bind.TransactOpts, sigs *validatorSignatures) (*types.Transaction, error)
+}
+
+// Connects to the Validator Manager Contract on the Ethereum network specified in the loom config.
+func dialVMC(vmcAddr common.Address) (*ethclient.Client, *ethcontract.ValidatorManagerContract, error) {
+	ethClient, err := dialEthereum()
+	if err != nil {
+		return nil, nil, err
+	}
+	vmc, err := connectToVMC(ethClient, vmcAddr)
+	if err != nil {
+		ethClient.Close()
+		return nil, nil, err
+	}
+	return ethClient, vmc, nil
+}
+
+// Signs the action with the validator keys specified via the flags, and submits it to the
+// Validator Manager Contract. The state of the contract is printed before & after the change.
+func executeVMCAction(flags *validatorSignerFlags, action *vmcAction) error {
+	signerKeys, err := flags.signerKeys()
+	if err != nil {
+		return err
+	}
+	senderKey, err := flags.senderKey()
+	if err != nil {
+		return err
+	}
+
+	vmcAddr, err := flags.vmcAddress()
+	if err != nil {
+		return err
+	}
+
+	ethClient, vmc, err := dialVMC(vmcAddr)
+	if err != nil {
+		return err
+	}
+	defer ethClient.Close()
+
+	current, err := loadValidatorSet(vmc, vmcAddr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(textOut, "before:")
+	current.print()
+
+	sigs, err := current.sign(current.createMessage(action.argsHash), signerKeys)
+	if err != nil {
+		return err
+	}
+	return submitVMCAction(ethClient, vmc, current, action, sigs, senderKey, flags.ConfirmTimeout)
+}
+
+// Checks the signatures meet the threshold, and submits the action to the Validator Manager
+// Contract, unless running in dry-run mode.
+func submitVMCAction(
+	ethClient *ethclient.Client, vmc *ethcontract.ValidatorManagerContract, current *validatorSet,
+	action *vmcAction, sigs *validatorSignatures, senderKey *ecdsa.PrivateKey, timeoutSecs int,
+) error {
+	if err := current.checkThreshold(sigs); err != nil {
+		return err
+	}
+
+	var msgHash [32]byte
+	copy(msgHash[:], current.createMessage(action.argsHash))
+	if err := vmc.CheckThreshold(nil, msgHash, sigs.SignersIndexes, sigs.V, sigs.R, sigs.S); err != nil {
+		return errors.Wrap(err, "signatures rejected by Validator Manager Contract")
+	}
+
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	if cmdFlags.DryRun {
+		printPlanStep("%s at nonce %v of %s, signed by %d validators with %v of %v power, sent by %s",
+			action.description, current.Nonce, current.Address.Hex(), len(sigs.SignersIndexes),
+			sigs.VotedPower, current.TotalPower, sender.Hex(),
+		)
+		return nil
+	}
+
+	err := sendEthereumTx(ethClient, action.name, senderKey, timeoutSecs, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return action.submit(vmc, opts, sigs)
+	})
+	if err != nil {
+		return err
+	}
+
+	updated, err := loadValidatorSet(vmc, current.Address)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(textOut, "after:")
+	updated.print()
+	return nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type validatorReport struct {
+	Address  string  `json:"address"`
+	Power    uint64  `json:"power"`
+	SharePct float64 `json:"share_pct"`
+}
+
+type validatorSetReport struct {
+	Address        string             `json:"address,omitempty"`
+	Nonce          *big.Int           `json:"nonce,omitempty"`
+	ThresholdNum   uint8              `json:"threshold_num,omitempty"`
+	ThresholdDenom uint8              `json:"threshold_denom,omitempty"`
+	TotalPower     *big.Int           `json:"total_power"`
+	Block          uint64             `json:"block,omitempty"`
+	TxHash         string             `json:"tx_hash,omitempty"`
+	Validators     []*validatorReport `json:"validators"`
+}
+
+type validatorCheckReport struct {
+	Address     string  `json:"address"`
+	IsValidator bool    `json:"is_validator"`
+	Index       int     `json:"index"`
+	Power       uint64  `json:"power"`
+	SharePct    float64 `json:"share_pct"`
+	// Whether the validator can meet the threshold on its own
+	MeetsThreshold bool `json:"meets_threshold"`
+}
+
+// Returns the given power as a percentage of the total power.
+func powerSharePct(power uint64, totalPower *big.Int) float64 {
+	if totalPower.Sign() == 0 {
+		return 0
+	}
+	share := new(big.Float).Quo(new(big.Float).SetUint64(power), new(big.Float).SetInt(totalPower))
+	pct, _ := share.Mul(share, big.NewFloat(100)).Float64()
+	return pct
+}
+
+func newValidatorSetReport(validators []common.Address, powers []uint64, totalPower *big.Int) *validatorSetReport {
+	r := &validatorSetReport{TotalPower: totalPower}
+	for i, validator := range validators {
+		r.Validators = append(r.Validators, &validatorReport{
+			Address:  validator.Hex(),
+			Power:    powers[i],
+			SharePct: powerSharePct(powers[i], totalPower),
+		})
+	}
+	return r
+}
+
+func (set *validatorSet) report() *validatorSetReport {
+	r := newValidatorSetReport(set.Validators, set.Powers, set.TotalPower)
+	r.Address = set.Address.Hex()
+	r.Nonce = set.Nonce
+	r.ThresholdNum = set.ThresholdNum
+	r.ThresholdDenom = set.ThresholdDenom
+	return r
+}
+
+// Returns the min power the signatures on a change must carry to meet the threshold.
+func (set *validatorSet) requiredPower() *big.Int {
+	// votedPower * denom >= totalPower * num, rounded up
+	required := new(big.Int).Mul(set.TotalPower, big.NewInt(int64(set.ThresholdNum)))
+	denom := big.NewInt(int64(set.ThresholdDenom))
+	required.Add(required, new(big.Int).Sub(denom, big.NewInt(1)))
+	return required.Div(required, denom)
+}
+
+func sortedAddresses(powers map[common.Address]uint64) []common.Address {
+	addrs := make([]common.Address, 0, len(powers))
+	for addr := range powers {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Hex() < addrs[j].Hex()
+	})
+	return addrs
+}
+
+func newValidatorsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validators",
+		Short: "Shows the validators registered in the Validator Manager Contract on Ethereum",
+	}
+	cmd.AddCommand(
+		newValidatorsListCmd(),
+		newValidatorsCheckCmd(),
+		newValidatorsHistoryCmd(),
+	)
+	return cmd
+}
+
+// Loads the current state of the Validator Manager Contract specified via --vmc.
+func loadCurrentValidatorSet(flags *validatorSignerFlags) (*validatorSet, error) {
+	vmcAddr, err := flags.vmcAddress()
+	if err != nil {
+		return nil, err
+	}
+	ethClient, vmc, err := dialVMC(vmcAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer ethClient.Close()
+	return loadValidatorSet(vmc, vmcAddr)
+}
+
+func newValidatorsListCmd() *cobra.Command {
+	var flags validatorSignerFlags
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the current validators with their powers, the threshold, and the nonce",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			set, err := loadCurrentValidatorSet(&flags)
+			if err != nil {
+				return err
+			}
+			r := set.report()
+			fmt.Fprintf(textOut, "Validator Manager Contract: %s\n", set.Address.Hex())
+			fmt.Fprintf(textOut, "nonce: %v, threshold: %d/%d (%v of %v total power)\n",
+				set.Nonce, set.ThresholdNum, set.ThresholdDenom, set.requiredPower(), set.TotalPower)
+			w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "INDEX\tVALIDATOR\tPOWER\tSHARE")
+			for i, validator := range r.Validators {
+				fmt.Fprintf(w, "%d\t%s\t%d\t%.2f%%\n", i, validator.Address, validator.Power, validator.SharePct)
+			}
+			w.Flush()
+			report.ValidatorSet = r
+			return nil
+		},
+	}
+	flags.registerVMCFlags(cmd)
+	return cmd
+}
+
+func newValidatorsCheckCmd() *cobra.Command {
+	var flags validatorSignerFlags
+	cmd := &cobra.Command{
+		Use:   "check <address>",
+		Short: "Checks if the given Ethereum address belongs to a current validator",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !common.IsHexAddress(args[0]) {
+				return errors.Errorf("invalid Ethereum address %s", args[0])
+			}
+			addr := common.HexToAddress(args[0])
+			set, err := loadCurrentValidatorSet(&flags)
+			if err != nil {
+				return err
+			}
+
+			r := &validatorCheckReport{Address: addr.Hex(), Index: set.indexOf(addr)}
+			report.ValidatorCheck = r
+			if r.Index < 0 {
+				fmt.Fprintf(textOut, "%s is not a validator of %s\n", r.Address, set.Address.Hex())
+				return nil
+			}
+			r.IsValidator = true
+			r.Power = set.Powers[r.Index]
+			r.SharePct = powerSharePct(r.Power, set.TotalPower)
+			r.MeetsThreshold = new(big.Int).SetUint64(r.Power).Cmp(set.requiredPower()) >= 0
+			fmt.Fprintf(textOut, "%s is validator #%d of %s, power: %d of %v (%.2f%%)\n",
+				r.Address, r.Index, set.Address.Hex(), r.Power, set.TotalPower, r.SharePct)
+			fmt.Fprintf(textOut, "nonce: %v, threshold: %d/%d, meets threshold on its own: %v\n",
+				set.Nonce, set.ThresholdNum, set.ThresholdDenom, r.MeetsThreshold)
+			return nil
+		},
+	}
+	flags.registerVMCFlags(cmd)
+	return cmd
+}
+
+func newValidatorsHistoryCmd() *cobra.Command {
+	var flags validatorSignerFlags
+	var fromBlock uint64
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Prints every validator set the Validator Manager Contract has had, oldest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vmcAddr, err := flags.vmcAddress()
+			if err != nil {
+				return err
+			}
+			ethClient, vmc, err := dialVMC(vmcAddr)
+			if err != nil {
+				return err
+			}
+			defer ethClient.Close()
+
+			changes, err := vmc.FilterValidatorSetChanged(&bind.FilterOpts{Start: fromBlock})
+			if err != nil {
+				return errors.Wrap(err, "failed to fetch validator set changes")
+			}
+			defer changes.Close()
+
+			fmt.Fprintf(textOut, "Validator Manager Contract: %s\n", vmcAddr.Hex())
+			prevPowers := map[common.Address]uint64{}
+			for changes.Next() {
+				event := changes.Event
+				totalPower := new(big.Int)
+				for _, power := range event.Powers {
+					totalPower.Add(totalPower, new(big.Int).SetUint64(power))
+				}
+				r := newValidatorSetReport(event.Validators, event.Powers, totalPower)
+				r.Block = event.Raw.BlockNumber
+				r.TxHash = event.Raw.TxHash.Hex()
+
+				fmt.Fprintf(textOut, "\nblock %d, tx %s, %d validators, total power: %v\n",
+					r.Block, r.TxHash, len(r.Validators), r.TotalPower)
+				w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "VALIDATOR\tPOWER\tSHARE\tCHANGE")
+				powers := map[common.Address]uint64{}
+				for i, validator := range event.Validators {
+					power := event.Powers[i]
+					powers[validator] = power
+					change := "-"
+					if prevPower, ok := prevPowers[validator]; !ok {
+						change = "added"
+					} else if prevPower != power {
+						change = fmt.Sprintf("power %d -> %d", prevPower, power)
+					}
+					fmt.Fprintf(w, "%s\t%d\t%.2f%%\t%s\n", validator.Hex(), power, r.Validators[i].SharePct, change)
+				}
+				for _, validator := range sortedAddresses(prevPowers) {
+					if _, ok := powers[validator]; !ok {
+						fmt.Fprintf(w, "%s\t-\t-\tremoved\n", validator.Hex())
+					}
+				}
+				w.Flush()
+				prevPowers = powers
+				report.ValidatorHistory = append(report.ValidatorHistory, r)
+			}
+			if err := changes.Error(); err != nil {
+				return errors.Wrap(err, "failed to fetch validator set changes")
+			}
+			fmt.Fprintf(textOut, "\nfound %d validator set changes\n", len(report.ValidatorHistory))
+			return nil
+		},
+	}
+	flags.registerVMCFlags(cmd)
+	cmd.Flags().Uint64Var(&fromBlock, "from-block", 0, "Block to start scanning for validator set changes from")
+	return cmd
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testValidator1 = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testValidator2 = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	testVMCAddr    = common.HexToAddress("0x3333333333333333333333333333333333333333")
+)
+
+// The expected hashes were computed independently of the deployer, by hashing the bytes that
+// abi.encodePacked produces for the same args in ValidatorManagerContract.sol.
+func TestHashValidatorSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		validators []common.Address
+		powers     []uint64
+		want       string
+	}{
+		{
+			name:       "two validators",
+			validators: []common.Address{testValidator1, testValidator2},
+			powers:     []uint64{10, 20},
+			want:       "006fe4f23cab539f28e6e1268334fb8ffc15e2950d924869b526093abdc684a5",
+		},
+		{
+			name:       "max power",
+			validators: []common.Address{testValidator1},
+			powers:     []uint64{^uint64(0)},
+			want:       "a3adfdb0cb0925d8732df012262293865ee28be477ceee696a37504d3eecea52",
+		},
+		{
+			name: "empty set",
+			want: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hash := hashValidatorSet(test.validators, test.powers)
+			require.Equal(t, test.want, hex.EncodeToString(hash))
+			action := newRotateValidatorsAction(test.validators, test.powers)
+			require.Equal(t, test.want, hex.EncodeToString(action.argsHash))
+		})
+	}
+}
+
+func TestValidatorSetCreateMessage(t *testing.T) {
+	set := &validatorSet{Address: testVMCAddr, Nonce: big.NewInt(5)}
+	hash := hashValidatorSet([]common.Address{testValidator1, testValidator2}, []uint64{10, 20})
+	require.Equal(t,
+		"53322a1343697ec27c81e9c5ad4efe802e09c9d8bfe731bc7bd5ff327de0becb",
+		hex.EncodeToString(set.createMessage(hash)),
+	)
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateway"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/loomnetwork/go-loom"
+	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
+	loom_client "github.com/loomnetwork/go-loom/client"
+	gw_v2 "github.com/loomnetwork/go-loom/client/gateway_v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Flags shared by the commands that act on behalf of a Gateway user, the user needs both an
+// Ethereum & a DAppChain key.
+type gatewayUserFlags struct {
+	Account          string
+	EthKeyFile       string
+	DAppChainKeyFile string
+	GatewayAddress   string
+}
+
+func (f *gatewayUserFlags) registerUserFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.Account, "account", "alice",
+		"Test account of the user, ignored if --eth-key & --dappchain-key are set")
+	flags.StringVar(&f.EthKeyFile, "eth-key", "", "File containing the hex encoded Ethereum private key of the user")
+	flags.StringVar(&f.DAppChainKeyFile, "dappchain-key", "",
+		"File containing the base64 encoded DAppChain private key of the user")
+	flags.StringVar(&f.GatewayAddress, "gateway", "",
+		"Address of the Ethereum Gateway, defaults to mainnet_gateway_addr in the deployment file, "+
+			"or mainnet_loomGateway_addr for LOOM")
+}
+
+func (f *gatewayUserFlags) identity(chainID string) (*loom_client.Identity, error) {
+	if f.EthKeyFile == "" && f.DAppChainKeyFile == "" {
+		identity, err := newTestAccountIdentityFunc(ethereumChain, chainID)(f.Account)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create identity for %s", f.Account)
+		}
+		return identity, nil
+	}
+	if f.EthKeyFile == "" || f.DAppChainKeyFile == "" {
+		return nil, errors.New("--eth-key & --dappchain-key must be set together")
+	}
+	ethKey, err := ioutil.ReadFile(f.EthKeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", f.EthKeyFile)
+	}
+	dappchainKey, err := ioutil.ReadFile(f.DAppChainKeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", f.DAppChainKeyFile)
+	}
+	identity, err := loom_client.CreateIdentityStr(
+		strings.TrimPrefix(strings.TrimSpace(string(ethKey)), "0x"),
+		strings.TrimSpace(string(dappchainKey)),
+		chainID,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create identity from key files")
+	}
+	return identity, nil
+}
+
+// gatewayUserSession holds the clients needed to move tokens between Ethereum & the DAppChain on
+// behalf of a user.
+type gatewayUserSession struct {
+	flags            *gatewayUserFlags
+	kind             tgtypes.TransferGatewayTokenKind
+	identity         *loom_client.Identity
+	deploymentInfo   *gateway.DeploymentInfo
+	loomCfg          *gateway.LoomConfig
+	loomClient       *loom_client.DAppChainRPCClient
+	ethClient        *ethclient.Client
+	dappchainGateway *gw_v2.DAppChainGateway
+	mainnetGateway   *gw_v2.MainnetGatewayClient
+}
+
+// Connects to the Gateways that handle the given kind of token, LOOM is handled by the Loom
+// Gateways, all other tokens by the main Gateways.
+func connectAsGatewayUser(flags *gatewayUserFlags, kind tgtypes.TransferGatewayTokenKind) (*gatewayUserSession, error) {
+	loomCfg, err := gateway.ParseConfig([]string{cmdFlags.LoomDir})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse loom config")
+	}
+	identity, err := flags.identity(loomCfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	deploymentInfo, err := gateway.LoadDeploymentInfo(cmdFlags.EthereumDeploymentInfoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load deployment info file")
+	}
+	ethClient, err := dialEthereum()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &gatewayUserSession{
+		flags:          flags,
+		kind:           kind,
+		identity:       identity,
+		deploymentInfo: deploymentInfo,
+		loomCfg:        loomCfg,
+		loomClient: loom_client.NewDAppChainRPCClient(
+			loomCfg.ChainID,
+			loomCfg.TransferGateway.DAppChainWriteURI,
+			loomCfg.TransferGateway.DAppChainReadURI,
+		),
+		ethClient: ethClient,
+	}
+	s.dappchainGateway, s.mainnetGateway, err = s.connectGateways(kind == tgtypes.TransferGatewayTokenKind_LOOMCOIN)
+	if err != nil {
+		ethClient.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Connects to the DAppChain & Ethereum LOOM Gateways, or the main Gateways. --gateway only
+// overrides the address of the Ethereum Gateway that handles the kind of token the session is for.
+func (s *gatewayUserSession) connectGateways(loomGateways bool) (*gw_v2.DAppChainGateway, *gw_v2.MainnetGatewayClient, error) {
+	gatewayAddrKey := "mainnet_gateway_addr"
+	if loomGateways {
+		gatewayAddrKey = "mainnet_loomGateway_addr"
+	}
+	gatewayAddr := ""
+	if loomGateways == (s.kind == tgtypes.TransferGatewayTokenKind_LOOMCOIN) {
+		gatewayAddr = s.flags.GatewayAddress
+	}
+	if gatewayAddr == "" {
+		if err := s.deploymentInfo.Require(gatewayAddrKey); err != nil {
+			return nil, nil, err
+		}
+		gatewayAddr = s.deploymentInfo.Get(gatewayAddrKey)
+	}
+	if !common.IsHexAddress(gatewayAddr) {
+		return nil, nil, errors.Errorf("invalid Ethereum Gateway address %s", gatewayAddr)
+	}
+
+	eventsURI := s.loomCfg.TransferGateway.DAppChainEventsURI
+	var dappchainGateway *gw_v2.DAppChainGateway
+	var err error
+	if loomGateways {
+		dappchainGateway, err = gw_v2.ConnectToDAppChainLoomGateway(s.loomClient, eventsURI)
+	} else {
+		dappchainGateway, err = gw_v2.ConnectToDAppChainGateway(s.loomClient, eventsURI)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to connect to Gateway on DAppChain")
+	}
+	mainnetGateway, err := gw_v2.ConnectToMainnetGateway(s.ethClient, gatewayAddr)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to connect to Ethereum Gateway %s", gatewayAddr)
+	}
+	return dappchainGateway, mainnetGateway, nil
+}
+
+func (s *gatewayUserSession) Close() {
+	s.ethClient.Close()
+}
+
+// Returns the given address, or the address stored under the given key in the deployment file.
+func (s *gatewayUserSession) resolveAddress(addrOrKey string) (string, error) {
+	if common.IsHexAddress(addrOrKey) {
+		return addrOrKey, nil
+	}
+	addr := s.deploymentInfo.Get(addrOrKey)
+	if addr == "" {
+		return "", errors.Errorf("%s is neither an address nor a key in %s", addrOrKey, s.deploymentInfo.Filename())
+	}
+	return addr, nil
+}
+
+// Connects to the DAppChain token contract specified either by its address, or by the key of its
+// address in the deployment file.
+func (s *gatewayUserSession) dappchainToken(token string) (*loom_client.MirroredTokenContract, error) {
+	if token == "" {
+		return nil, errors.Errorf("--token must be set for %s tokens", gateway.TokenKindName(s.kind))
+	}
+	addrStr, err := s.resolveAddress(token)
+	if err != nil {
+		return nil, err
+	}
+	localAddr, err := loom.LocalAddressFromHexString(addrStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid DAppChain token address %s", addrStr)
+	}
+	addr := loom.Address{ChainID: s.loomClient.GetChainID(), Local: localAddr}
+	return gateway.ConnectToDAppChainToken(s.loomClient, s.kind, addr)
+}
+
+func (s *gatewayUserSession) withdrawer(pollInterval, timeout time.Duration) *gateway.Withdrawer {
+	return &gateway.Withdrawer{
+		LoomClient:       s.loomClient,
+		EthClient:        s.ethClient,
+		DAppChainGateway: s.dappchainGateway,
+		MainnetGateway:   s.mainnetGateway,
+		PollInterval:     pollInterval,
+		Timeout:          timeout,
+	}
+}
+
+type withdrawFlags struct {
+	gatewayUserFlags
+	Kind         string
+	Amount       string
+	TokenID      string
+	Token        string
+	ProgressFile string
+	Timeout      int
+	PollInterval int
+}
+
+var withdrawCmdFlags withdrawFlags
+
+func newWithdrawCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "withdraw",
+		Short: "Withdraws tokens from the DAppChain to Ethereum",
+		Long: "Runs a withdrawal through all its stages: approves the DAppChain Gateway to take the tokens, " +
+			"requests a withdrawal receipt, waits for the Oracle to sign it, withdraws the tokens from the " +
+			"Ethereum Gateway, and waits for the Oracle to clear the receipt. Progress is saved to the " +
+			"--progress file after every stage, so an interrupted withdrawal can be resumed by running the " +
+			"command again with the same flags.",
+		RunE: withdraw,
+	}
+	flags := cmd.Flags()
+	withdrawCmdFlags.registerUserFlags(cmd)
+	flags.StringVar(&withdrawCmdFlags.Kind, "kind", "", "Kind of token to withdraw: eth, loomcoin, erc20, erc721, or erc721x")
+	flags.StringVar(&withdrawCmdFlags.Amount, "amount", "",
+		"Amount to withdraw in the smallest unit of the token, required for all kinds except erc721")
+	flags.StringVar(&withdrawCmdFlags.TokenID, "token-id", "", "ID of the token to withdraw, required for erc721 & erc721x")
+	flags.StringVar(&withdrawCmdFlags.Token, "token", "",
+		"Address of the DAppChain token contract, or the key of the address in the deployment file, "+
+			"required for erc20, erc721 & erc721x")
+	flags.StringVar(&withdrawCmdFlags.ProgressFile, "progress", "",
+		"JSON file the withdrawal progress is saved to, defaults to withdrawal-<ethereum address>.json")
+	flags.IntVar(&withdrawCmdFlags.Timeout, "timeout", 300,
+		"Max number of seconds to wait for the Oracle or the DAppChain Gateway at each stage")
+	flags.IntVar(&withdrawCmdFlags.PollInterval, "poll-interval", 5,
+		"Number of seconds to wait between checks of the withdrawal receipt")
+	cmd.MarkFlagRequired("kind")
+	return cmd
+}
+
+func parseBigIntFlag(name, value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, errors.Errorf("--%s %s is not a valid integer", name, value)
+	}
+	return v, nil
+}
+
+func loadWithdrawalProgress(filename string) (*gateway.WithdrawalProgress, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+	var progress gateway.WithdrawalProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	return &progress, nil
+}
+
+func saveWithdrawalProgress(filename string, progress *gateway.WithdrawalProgress) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal withdrawal progress")
+	}
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", filename)
+	}
+	return nil
+}
+
+func withdraw(cmd *cobra.Command, args []string) error {
+	flags := withdrawCmdFlags
+	kind, err := gateway.ParseTokenKind(flags.Kind)
+	if err != nil {
+		return err
+	}
+	wd := &gateway.Withdrawal{Kind: kind}
+	if wd.Amount, err = parseBigIntFlag("amount", flags.Amount); err != nil {
+		return err
+	}
+	if wd.TokenID, err = parseBigIntFlag("token-id", flags.TokenID); err != nil {
+		return err
+	}
+
+	session, err := connectAsGatewayUser(&flags.gatewayUserFlags, kind)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	if kind != tgtypes.TransferGatewayTokenKind_ETH && kind != tgtypes.TransferGatewayTokenKind_LOOMCOIN {
+		if wd.TokenContract, err = session.dappchainToken(flags.Token); err != nil {
+			return err
+		}
+	}
+
+	progressFile := flags.ProgressFile
+	if progressFile == "" {
+		progressFile = fmt.Sprintf("withdrawal-%s.json", session.identity.MainnetAddr.Hex())
+	}
+	progress, err := loadWithdrawalProgress(progressFile)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = gateway.NewWithdrawalProgress(session.identity, wd)
+	} else if progress.Stage == gateway.WithdrawalCompleted {
+		return errors.Errorf("the withdrawal in %s has already been completed, remove the file to start a new one",
+			progressFile)
+	} else {
+		fmt.Fprintf(textOut, "resuming %s withdrawal from stage %s\n", progress.Kind, progress.Stage)
+	}
+	report.Withdrawal = progress
+
+	withdrawer := session.withdrawer(
+		time.Duration(flags.PollInterval)*time.Second,
+		time.Duration(flags.Timeout)*time.Second,
+	)
+	if cmdFlags.DryRun {
+		return printWithdrawalPlan(withdrawer, session.identity, wd, progress)
+	}
+
+	withdrawer.OnProgress = func(progress *gateway.WithdrawalProgress) error {
+		fmt.Fprintf(textOut, "withdrawal stage: %s\n", progress.Stage)
+		return saveWithdrawalProgress(progressFile, progress)
+	}
+	err = withdrawer.Withdraw(session.identity, wd, progress)
+	if _, statErr := os.Stat(progressFile); statErr == nil {
+		report.FilesWritten = append(report.FilesWritten, progressFile)
+	}
+	if err != nil {
+		if errors.Cause(err) == gateway.ErrPendingWithdrawal {
+			return errors.Wrap(err, "run withdrawals pending with the same account to inspect or complete it")
+		}
+		return errors.Wrapf(err, "withdrawal stopped at stage %s, run the command again to resume it", progress.Stage)
+	}
+	fmt.Fprintf(textOut, "withdrew %s to %s\n", progress.Kind, session.identity.MainnetAddr.Hex())
+	return nil
+}
+
+func printWithdrawalPlan(
+	withdrawer *gateway.Withdrawer, owner *loom_client.Identity, wd *gateway.Withdrawal, progress *gateway.WithdrawalProgress,
+) error {
+	pending, err := withdrawer.PendingWithdrawal(owner)
+	if err != nil {
+		return err
+	}
+	// Like Withdrawer.Withdraw, a receipt matching the withdrawal is taken to be its own receipt when
+	// the progress doesn't have the receipt nonce yet, e.g. because the withdrawal was interrupted
+	// right after it was requested.
+	if pending != nil && progress.WithdrawalNonce == nil && !pending.Matches(wd) {
+		printPlanStep("withdrawal receipt %d for %s is pending, it would have to be completed first",
+			pending.Receipt.WithdrawalNonce, gateway.TokenKindName(pending.Kind))
+		return nil
+	}
+	if pending != nil && progress.WithdrawalNonce != nil && *progress.WithdrawalNonce != pending.Receipt.WithdrawalNonce {
+		printPlanStep("withdrawal receipt %d is pending instead of receipt %d, it would have to be completed first",
+			pending.Receipt.WithdrawalNonce, *progress.WithdrawalNonce)
+		return nil
+	}
+	stage := progress.Stage
+	if pending != nil {
+		stage = pending.Stage()
+	}
+	switch stage {
+	case gateway.WithdrawalNotStarted:
+		printPlanStep("approve DAppChain Gateway %s to take the %s tokens", withdrawer.DAppChainGateway.Address, progress.Kind)
+		fallthrough
+	case gateway.WithdrawalApproved:
+		printPlanStep("request %s withdrawal receipt from DAppChain Gateway", progress.Kind)
+		fallthrough
+	case gateway.WithdrawalRequested:
+		printPlanStep("wait for the Oracle to sign the withdrawal receipt")
+		fallthrough
+	case gateway.WithdrawalSigned:
+		printPlanStep("withdraw %s from Ethereum Gateway %s", progress.Kind, withdrawer.MainnetGateway.Address.Hex())
+		fallthrough
+	case gateway.WithdrawalSubmitted:
+		printPlanStep("wait for the Oracle to clear the withdrawal receipt")
+	}
+	return nil
+}
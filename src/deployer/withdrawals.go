@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"gateway"
+	"math/big"
+
+	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
+	"github.com/spf13/cobra"
+)
+
+type pendingWithdrawalReport struct {
+	Owner           string   `json:"owner"`
+	Gateway         string   `json:"gateway"`
+	Kind            string   `json:"kind"`
+	TokenContract   string   `json:"token_contract,omitempty"`
+	TokenID         *big.Int `json:"token_id,omitempty"`
+	Amount          *big.Int `json:"amount,omitempty"`
+	WithdrawalNonce uint64   `json:"withdrawal_nonce"`
+	MainnetNonce    *big.Int `json:"mainnet_nonce"`
+	Signed          bool     `json:"signed"`
+	NonceConsumed   bool     `json:"nonce_consumed"`
+	Stage           string   `json:"stage"`
+	CanComplete     bool     `json:"can_complete"`
+	Completed       bool     `json:"completed"`
+}
+
+func newWithdrawalsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "withdrawals",
+		Short: "Inspects withdrawals from the DAppChain to Ethereum",
+	}
+	cmd.AddCommand(newWithdrawalsPendingCmd())
+	return cmd
+}
+
+type withdrawalsPendingFlags struct {
+	gatewayUserFlags
+	LoomCoin bool
+	Complete bool
+}
+
+var withdrawalsPendingCmdFlags withdrawalsPendingFlags
+
+func newWithdrawalsPendingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pending",
+		Short: "Shows the pending withdrawal of an account, and optionally completes it",
+		Long: "Reads the withdrawal receipt of the account from the DAppChain Gateway, and checks the " +
+			"withdrawal nonce of the account in the Ethereum Gateway to figure out if the withdrawal can be " +
+			"completed. With --complete a receipt signed by the Oracle is submitted to the Ethereum Gateway, " +
+			"which finishes a withdrawal that was interrupted after it was requested.",
+		RunE: withdrawalsPending,
+	}
+	withdrawalsPendingCmdFlags.registerUserFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVar(&withdrawalsPendingCmdFlags.LoomCoin, "loomcoin", false,
+		"Check the Loom Gateways, which handle LOOM withdrawals, instead of the main Gateways")
+	flags.BoolVar(&withdrawalsPendingCmdFlags.Complete, "complete", false,
+		"Submit the signed withdrawal receipt to the Ethereum Gateway")
+	return cmd
+}
+
+func withdrawalsPending(cmd *cobra.Command, args []string) error {
+	flags := withdrawalsPendingCmdFlags
+	kind := tgtypes.TransferGatewayTokenKind_ETH
+	if flags.LoomCoin {
+		kind = tgtypes.TransferGatewayTokenKind_LOOMCOIN
+	}
+	session, err := connectAsGatewayUser(&flags.gatewayUserFlags, kind)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	owner := session.identity.MainnetAddr.Hex()
+	withdrawer := session.withdrawer(0, 0)
+	pending, err := withdrawer.PendingWithdrawal(session.identity)
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		fmt.Fprintf(textOut, "%s has no pending withdrawal in DAppChain Gateway %s\n",
+			owner, session.dappchainGateway.Address)
+		return nil
+	}
+
+	r := &pendingWithdrawalReport{
+		Owner:           owner,
+		Gateway:         session.mainnetGateway.Address.Hex(),
+		Kind:            gateway.TokenKindName(pending.Kind),
+		TokenID:         pending.TokenID,
+		Amount:          pending.Amount,
+		WithdrawalNonce: pending.Receipt.WithdrawalNonce,
+		MainnetNonce:    pending.MainnetNonce,
+		Signed:          pending.Signed(),
+		NonceConsumed:   pending.NonceConsumed(),
+		Stage:           string(pending.Stage()),
+	}
+	if pending.Kind != tgtypes.TransferGatewayTokenKind_ETH {
+		r.TokenContract = pending.TokenContract.Hex()
+	}
+	r.CanComplete = r.Signed && !r.NonceConsumed
+	report.PendingWithdrawal = r
+
+	fmt.Fprintf(textOut, "%s has a pending %s withdrawal, receipt nonce: %d, Ethereum Gateway nonce: %v\n",
+		owner, r.Kind, r.WithdrawalNonce, r.MainnetNonce)
+	if r.TokenContract != "" {
+		fmt.Fprintf(textOut, "token contract: %s\n", r.TokenContract)
+	}
+	if r.TokenID != nil && r.TokenID.Sign() != 0 {
+		fmt.Fprintf(textOut, "token ID: %v\n", r.TokenID)
+	}
+	if r.Amount != nil && r.Amount.Sign() != 0 {
+		fmt.Fprintf(textOut, "amount: %v\n", r.Amount)
+	}
+
+	switch {
+	case r.NonceConsumed:
+		fmt.Fprintln(textOut, "the withdrawal has been completed on Ethereum, the Oracle will clear the receipt")
+		return nil
+	case !r.Signed:
+		fmt.Fprintln(textOut, "the receipt hasn't been signed by the Oracle yet, the withdrawal can't be completed")
+		return nil
+	case !flags.Complete:
+		fmt.Fprintf(textOut, "the receipt has been signed by the Oracle, run again with --complete to withdraw from %s\n",
+			r.Gateway)
+		return nil
+	case cmdFlags.DryRun:
+		printPlanStep("withdraw %s from Ethereum Gateway %s with receipt %d", r.Kind, r.Gateway, r.WithdrawalNonce)
+		return nil
+	}
+
+	if err := withdrawer.CompleteWithdrawal(session.identity, pending); err != nil {
+		return err
+	}
+	r.Completed = true
+	fmt.Fprintf(textOut, "withdrew %s from Ethereum Gateway %s, the Oracle will clear the receipt\n", r.Kind, r.Gateway)
+	return nil
+}
@@ -107,14 +107,21 @@ func (s *BinanceTransferGatewayTestSuite) SetupSuite() {
 
 	s.loomCoin, err = native_coin.ConnectToDAppChainLoomContract(s.loomClient)
 	require.NoError(err)
-	dappbnbTokenaddr := GetMainnetContractCfgString("loomchain_bnb_token_addr")
+	deploymentInfo, err := GetDeploymentInfo()
+	require.NoError(err)
+	require.NoError(deploymentInfo.Require(
+		"loomchain_bnb_token_addr",
+		"loomchain_bep2_token_addr",
+	))
+
+	dappbnbTokenaddr := deploymentInfo.LoomchainBNBTokenAddr
 	mirroredBNBTokenContract, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleBEP2Token.abi",
 		"SampleBEP2Token", loom.MustParseAddress("default:"+dappbnbTokenaddr))
 	require.NoError(err)
 	s.bnbToken = &erc20.DAppChainERC20Contract{MirroredTokenContract: mirroredBNBTokenContract}
 	require.NoError(err)
 
-	dappbep2Tokenaddr := GetMainnetContractCfgString("loomchain_bep2_token_addr")
+	dappbep2Tokenaddr := deploymentInfo.LoomchainBEP2TokenAddr
 	mirroredBEP2TokenContract, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleBEP2Token.abi",
 		"SampleBEP2Token", loom.MustParseAddress("default:"+dappbep2Tokenaddr))
 	require.NoError(err)
@@ -0,0 +1,289 @@
+package gateway
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	loom "github.com/loomnetwork/go-loom"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Address formats that can be specified via the format tag of DeploymentInfo fields.
+const (
+	// Hex encoded Ethereum address
+	formatEthAddress = "eth"
+	// Hex encoded Tron address, which is an Ethereum address prefixed by 41
+	formatTronAddress = "tron"
+	// Hex encoded DAppChain address
+	formatLocalAddress = "local"
+	// Hex encoded tx hash
+	formatTxHash = "tx"
+)
+
+var formatDescriptions = map[string]string{
+	formatEthAddress:   "Ethereum address",
+	formatTronAddress:  "Tron address",
+	formatLocalAddress: "DAppChain address",
+	formatTxHash:       "tx hash",
+}
+
+// DeploymentInfo contains the addresses of the contracts deployed for the e2e tests, along with the
+// hashes of the txs that deployed them. It's loaded from contracts.yml in the e2e config dir, which
+// is written to by the Truffle migrations & the deployer.
+type DeploymentInfo struct {
+	// Ethereum or Tron contracts
+	MainnetGatewayAddr             string `yaml:"mainnet_gateway_addr" format:"eth,tron"`
+	MainnetLoomGatewayAddr         string `yaml:"mainnet_loomGateway_addr" format:"eth,tron"`
+	MainnetVMCAddr                 string `yaml:"mainnet_validatorManagerContract_addr" format:"eth"`
+	LoomTokenAddr                  string `yaml:"loomtoken_addr" format:"eth,tron"`
+	MainnetCryptoCardsAddr         string `yaml:"mainnet_crypto_cards_addr" format:"eth"`
+	MainnetCryptoCardsTx           string `yaml:"mainnet_crypto_cards_tx" format:"tx"`
+	MainnetGameTokenAddr           string `yaml:"mainnet_game_token_addr" format:"eth"`
+	MainnetGameTokenTx             string `yaml:"mainnet_game_token_tx" format:"tx"`
+	MainnetERC721XCardsAddr        string `yaml:"mainnet_erc721x_cards_addr" format:"eth"`
+	MainnetERC721XCardsTx          string `yaml:"mainnet_erc721x_cards_tx" format:"tx"`
+	MainnetERC20MintableTokenAddr  string `yaml:"mainnet_erc20_mintable_token_addr" format:"eth"`
+	MainnetERC20MintableTokenTx    string `yaml:"mainnet_erc20_mintable_token_tx" format:"tx"`
+	MainnetERC721MintableTokenAddr string `yaml:"mainnet_erc721_mintable_token_addr" format:"eth"`
+	MainnetERC721MintableTokenTx   string `yaml:"mainnet_erc721_mintable_token_tx" format:"tx"`
+
+	// DAppChain contracts
+	LoomchainCryptoCardsAddr         string `yaml:"loomchain_crypto_cards_addr" format:"local"`
+	LoomchainERC721XTokenAddr        string `yaml:"loomchain_SampleERC721XToken_1" format:"local"`
+	LoomchainERC20TokenAddr          string `yaml:"loomchain_SampleERC20Token_1" format:"local"`
+	LoomchainERC20Token2Addr         string `yaml:"loomchain_SampleERC20Token_2" format:"local"`
+	LoomchainERC721MintableTokenAddr string `yaml:"loomchain_erc721_mintable_token_addr" format:"local"`
+	LoomchainTRXTokenAddr            string `yaml:"loomchain_trx_token_addr" format:"local"`
+	LoomchainTRC20TokenAddr          string `yaml:"loomchain_trc20_token_addr" format:"local"`
+	LoomchainBNBTokenAddr            string `yaml:"loomchain_bnb_token_addr" format:"local"`
+	LoomchainBEP2TokenAddr           string `yaml:"loomchain_bep2_token_addr" format:"local"`
+
+	// Binance Chain tokens
+	BinanceBEP2TokenSymbol string `yaml:"binance_bep2_token_symbol"`
+
+	filename string
+	// All the settings in the file, including the ones that don't correspond to any of the fields
+	// above, so they can be written back without losing anything.
+	settings yaml.MapSlice
+}
+
+// NewDeploymentInfo creates an empty DeploymentInfo that will be written to the given file.
+func NewDeploymentInfo(filename string) *DeploymentInfo {
+	return &DeploymentInfo{filename: filename}
+}
+
+// LoadDeploymentInfo loads & validates the deployment info from the given YAML file.
+func LoadDeploymentInfo(filename string) (*DeploymentInfo, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	info := NewDeploymentInfo(filename)
+	if err := yaml.Unmarshal(data, &info.settings); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	for _, item := range info.settings {
+		if field, ok := info.field(fmt.Sprint(item.Key)); ok && item.Value != nil {
+			field.SetString(fmt.Sprint(item.Value))
+		}
+	}
+	if err := info.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid deployment info in %s", filename)
+	}
+	return info, nil
+}
+
+// LoadOrCreateDeploymentInfo loads the deployment info from the given YAML file, or creates an
+// empty DeploymentInfo if the file doesn't exist yet.
+func LoadOrCreateDeploymentInfo(filename string) (*DeploymentInfo, error) {
+	info, err := LoadDeploymentInfo(filename)
+	if os.IsNotExist(errors.Cause(err)) {
+		return NewDeploymentInfo(filename), nil
+	}
+	return info, err
+}
+
+// Filename returns the name of the file the deployment info is loaded from & saved to.
+func (info *DeploymentInfo) Filename() string {
+	return info.filename
+}
+
+// Get returns the value of the given key, keys are case-insensitive. Keys that don't correspond to
+// any of the DeploymentInfo fields are looked up in the settings loaded from the file.
+func (info *DeploymentInfo) Get(key string) string {
+	if field, ok := info.field(key); ok {
+		return field.String()
+	}
+	for _, item := range info.settings {
+		if strings.EqualFold(fmt.Sprint(item.Key), key) && item.Value != nil {
+			return fmt.Sprint(item.Value)
+		}
+	}
+	return ""
+}
+
+// Set changes the value of the given key, the value is validated if the key corresponds to one of
+// the DeploymentInfo fields. Changes are only written to the file by Save.
+func (info *DeploymentInfo) Set(key, value string) error {
+	if field, ok := info.field(key); ok {
+		if err := validateFormat(deploymentInfoKeys[strings.ToLower(key)].format, value); err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+		field.SetString(value)
+		return nil
+	}
+	info.setSetting(key, value)
+	return nil
+}
+
+// Require returns an error listing any of the given keys that are missing, or don't correspond to
+// any of the DeploymentInfo fields.
+func (info *DeploymentInfo) Require(keys ...string) error {
+	var missing, unknown []string
+	for _, key := range keys {
+		field, ok := info.field(key)
+		if !ok {
+			unknown = append(unknown, key)
+		} else if field.String() == "" {
+			missing = append(missing, key)
+		}
+	}
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		problems = append(problems, "unknown "+strings.Join(unknown, ", "))
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("%s: %s", info.filename, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Validate checks the format of all the addresses & tx hashes that have been set.
+func (info *DeploymentInfo) Validate() error {
+	var problems []string
+	v := reflect.ValueOf(info).Elem()
+	for _, k := range deploymentInfoKeyList {
+		value := v.Field(k.index).String()
+		if value == "" {
+			continue
+		}
+		if err := validateFormat(k.format, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", k.name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Save writes the deployment info back to the file it was loaded from, settings that don't
+// correspond to any of the DeploymentInfo fields are preserved.
+func (info *DeploymentInfo) Save() error {
+	v := reflect.ValueOf(info).Elem()
+	for _, k := range deploymentInfoKeyList {
+		if value := v.Field(k.index).String(); value != "" {
+			info.setSetting(k.name, value)
+		}
+	}
+	data, err := yaml.Marshal(info.settings)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(info.filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(info.filename, data, 0644)
+}
+
+func (info *DeploymentInfo) field(key string) (reflect.Value, bool) {
+	k, ok := deploymentInfoKeys[strings.ToLower(key)]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(info).Elem().Field(k.index), true
+}
+
+func (info *DeploymentInfo) setSetting(key, value string) {
+	for i, item := range info.settings {
+		if strings.EqualFold(fmt.Sprint(item.Key), key) {
+			info.settings[i].Value = value
+			return
+		}
+	}
+	info.settings = append(info.settings, yaml.MapItem{Key: key, Value: value})
+}
+
+type deploymentInfoKey struct {
+	name   string
+	index  int
+	format string
+}
+
+// Maps the lowercased YAML keys to the corresponding DeploymentInfo fields.
+var deploymentInfoKeys = map[string]deploymentInfoKey{}
+var deploymentInfoKeyList []deploymentInfoKey
+
+func init() {
+	t := reflect.TypeOf(DeploymentInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		if name == "" {
+			continue
+		}
+		k := deploymentInfoKey{
+			name:   name,
+			index:  i,
+			format: t.Field(i).Tag.Get("format"),
+		}
+		deploymentInfoKeys[strings.ToLower(name)] = k
+		deploymentInfoKeyList = append(deploymentInfoKeyList, k)
+	}
+}
+
+// Checks that the value matches one of the comma separated formats.
+func validateFormat(formats string, value string) error {
+	if formats == "" {
+		return nil
+	}
+	for _, format := range strings.Split(formats, ",") {
+		switch format {
+		case formatEthAddress:
+			if strings.HasPrefix(value, "0x") && common.IsHexAddress(value) {
+				return nil
+			}
+		case formatTronAddress:
+			if len(value) == 42 && strings.HasPrefix(value, "41") && isHex(value) {
+				return nil
+			}
+		case formatLocalAddress:
+			if _, err := loom.LocalAddressFromHexString(value); err == nil {
+				return nil
+			}
+		case formatTxHash:
+			if len(value) == 66 && strings.HasPrefix(value, "0x") && isHex(value[2:]) {
+				return nil
+			}
+		}
+	}
+	var descriptions []string
+	for _, format := range strings.Split(formats, ",") {
+		descriptions = append(descriptions, formatDescriptions[format])
+	}
+	return errors.Errorf("%q is not a valid %s", value, strings.Join(descriptions, " or "))
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
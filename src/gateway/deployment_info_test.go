@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	testEthAddr  = "0x1111111111111111111111111111111111111111"
+	testTronAddr = "411111111111111111111111111111111111111111"
+	testTxHash   = "0x2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		formats string
+		value   string
+		valid   bool
+	}{
+		{formats: formatEthAddress, value: testEthAddr, valid: true},
+		{formats: formatEthAddress, value: strings.TrimPrefix(testEthAddr, "0x")},
+		{formats: formatEthAddress, value: "0x1234"},
+		{formats: formatEthAddress, value: testTronAddr},
+		{formats: formatTronAddress, value: testTronAddr, valid: true},
+		{formats: formatTronAddress, value: testEthAddr},
+		{formats: formatTronAddress, value: "41zz11111111111111111111111111111111111111"},
+		{formats: formatEthAddress + "," + formatTronAddress, value: testEthAddr, valid: true},
+		{formats: formatEthAddress + "," + formatTronAddress, value: testTronAddr, valid: true},
+		{formats: formatEthAddress + "," + formatTronAddress, value: testTxHash},
+		{formats: formatLocalAddress, value: testEthAddr, valid: true},
+		{formats: formatLocalAddress, value: strings.TrimPrefix(testEthAddr, "0x")},
+		{formats: formatLocalAddress, value: "0x1234"},
+		{formats: formatTxHash, value: testTxHash, valid: true},
+		{formats: formatTxHash, value: strings.TrimPrefix(testTxHash, "0x")},
+		{formats: formatTxHash, value: testEthAddr},
+		{formats: formatTxHash, value: "0x" + strings.Repeat("zz", 32)},
+		{formats: "", value: "anything goes", valid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.formats+"/"+test.value, func(t *testing.T) {
+			err := validateFormat(test.formats, test.value)
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+// One key of each format, with a valid and an invalid value.
+var deploymentInfoFormatTests = []struct {
+	key     string
+	valid   string
+	invalid string
+}{
+	{key: "mainnet_gateway_addr", valid: testTronAddr, invalid: testTxHash},
+	{key: "mainnet_validatorManagerContract_addr", valid: testEthAddr, invalid: testTronAddr},
+	{key: "loomchain_crypto_cards_addr", valid: testEthAddr, invalid: "0x1234"},
+	{key: "mainnet_crypto_cards_tx", valid: testTxHash, invalid: testEthAddr},
+}
+
+func TestDeploymentInfoSetAndSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deployment-info")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, test := range deploymentInfoFormatTests {
+		t.Run(test.key, func(t *testing.T) {
+			filename := filepath.Join(dir, test.key, "contracts.yml")
+			info := NewDeploymentInfo(filename)
+			require.Error(t, info.Set(test.key, test.invalid))
+			require.Equal(t, "", info.Get(test.key))
+
+			require.NoError(t, info.Set(test.key, test.valid))
+			require.NoError(t, info.Save())
+
+			loaded, err := LoadDeploymentInfo(filename)
+			require.NoError(t, err)
+			require.Equal(t, test.valid, loaded.Get(test.key))
+			require.NoError(t, loaded.Require(test.key))
+		})
+	}
+}
+
+func TestLoadDeploymentInfoRejectsInvalidValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deployment-info")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, test := range deploymentInfoFormatTests {
+		t.Run(test.key, func(t *testing.T) {
+			filename := filepath.Join(dir, test.key+".yml")
+			data := test.key + ": \"" + test.invalid + "\"\n"
+			require.NoError(t, ioutil.WriteFile(filename, []byte(data), 0644))
+			_, err := LoadDeploymentInfo(filename)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.key)
+		})
+	}
+}
+
+func TestDeploymentInfoRoundTripKeepsUnknownKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deployment-info")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "contracts.yml")
+	data := "truffle_only_addr: " + testEthAddr + "\n" +
+		"mainnet_gateway_addr: " + testEthAddr + "\n" +
+		"truffle_block_number: 42\n"
+	require.NoError(t, ioutil.WriteFile(filename, []byte(data), 0644))
+
+	info, err := LoadDeploymentInfo(filename)
+	require.NoError(t, err)
+	require.Equal(t, testEthAddr, info.MainnetGatewayAddr)
+	// Keys that don't correspond to any of the fields are readable too, and case-insensitive
+	require.Equal(t, testEthAddr, info.Get("TRUFFLE_ONLY_ADDR"))
+	require.Equal(t, "42", info.Get("truffle_block_number"))
+	require.Error(t, info.Require("truffle_only_addr"))
+
+	require.NoError(t, info.Set("mainnet_crypto_cards_tx", testTxHash))
+	require.NoError(t, info.Set("deployer_note", "added by the deployer"))
+	require.NoError(t, info.Save())
+
+	// Existing keys keep their place in the file, new ones are appended
+	saved, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	var settings yaml.MapSlice
+	require.NoError(t, yaml.Unmarshal(saved, &settings))
+	var keys []string
+	for _, item := range settings {
+		keys = append(keys, fmt.Sprint(item.Key))
+	}
+	require.Equal(t, []string{
+		"truffle_only_addr", "mainnet_gateway_addr", "truffle_block_number", "deployer_note", "mainnet_crypto_cards_tx",
+	}, keys)
+
+	reloaded, err := LoadDeploymentInfo(filename)
+	require.NoError(t, err)
+	require.Equal(t, testEthAddr, reloaded.MainnetGatewayAddr)
+	require.Equal(t, testTxHash, reloaded.MainnetCryptoCardsTx)
+	require.Equal(t, testEthAddr, reloaded.Get("truffle_only_addr"))
+	require.Equal(t, "42", reloaded.Get("truffle_block_number"))
+	require.Equal(t, "added by the deployer", reloaded.Get("deployer_note"))
+}
+
+func TestLoadOrCreateDeploymentInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deployment-info")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "missing", "contracts.yml")
+	info, err := LoadOrCreateDeploymentInfo(filename)
+	require.NoError(t, err)
+	require.Equal(t, filename, info.Filename())
+	require.Error(t, info.Require("mainnet_gateway_addr"))
+	require.Error(t, info.Require("not_a_deployment_key"))
+}
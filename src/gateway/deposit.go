@@ -0,0 +1,352 @@
+// +build evm
+
+package gateway
+
+import (
+	"bytes"
+	"client"
+	"context"
+	"ethcontract"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
+	loomclient "github.com/loomnetwork/go-loom/client"
+	"github.com/loomnetwork/go-loom/client/erc20"
+	"github.com/loomnetwork/go-loom/client/erc721"
+	"github.com/loomnetwork/go-loom/client/erc721x"
+	gw "github.com/loomnetwork/go-loom/client/gateway_v2"
+	"github.com/loomnetwork/go-loom/client/native_coin"
+	"github.com/pkg/errors"
+)
+
+// Deposit describes the tokens that should be deposited from Ethereum to the DAppChain.
+type Deposit struct {
+	Kind tgtypes.TransferGatewayTokenKind
+	// Amount of ETH, LOOM, ERC20, or ERC721X tokens
+	Amount *big.Int
+	// ERC721 or ERC721X token ID
+	TokenID *big.Int
+	// Ethereum token contract, not used for ETH, defaults to the LOOM token for LOOM
+	TokenContract common.Address
+	// DAppChain token contract the Ethereum token is mapped to, not used for ETH & LOOM, including
+	// ERC20 deposits of the LOOM token
+	DAppChainToken *loomclient.MirroredTokenContract
+	// Transfer ERC20 or LOOM tokens straight to the Gateway, and submit the hash of the transfer tx
+	// to the DAppChain Gateway, instead of calling depositERC20
+	HotWallet bool
+	// Transfer the ERC721 token to the Gateway with safeTransferFrom even if the token contract
+	// implements depositToGateway like the crypto cards contract does, contracts that don't are
+	// always sent with safeTransferFrom
+	SafeTransfer bool
+}
+
+func (dep *Deposit) validate() error {
+	if _, ok := tokenKindNames[dep.Kind]; !ok {
+		return errors.Errorf("unsupported token kind %v", dep.Kind)
+	}
+	if dep.Kind != tgtypes.TransferGatewayTokenKind_ERC721 && (dep.Amount == nil || dep.Amount.Sign() <= 0) {
+		return errors.Errorf("%s deposit requires a positive amount", TokenKindName(dep.Kind))
+	}
+	if (dep.Kind == tgtypes.TransferGatewayTokenKind_ERC721 || dep.Kind == tgtypes.TransferGatewayTokenKind_ERC721X) &&
+		dep.TokenID == nil {
+		return errors.Errorf("%s deposit requires a token ID", TokenKindName(dep.Kind))
+	}
+	if _, ok := tokenKindContracts[dep.Kind]; ok {
+		if dep.TokenContract == (common.Address{}) {
+			return errors.Errorf("%s deposit requires an Ethereum token contract", TokenKindName(dep.Kind))
+		}
+	}
+	if dep.HotWallet && dep.Kind != tgtypes.TransferGatewayTokenKind_ERC20 &&
+		dep.Kind != tgtypes.TransferGatewayTokenKind_LOOMCOIN {
+		return errors.Errorf("%s tokens can't be deposited via the hot wallet", TokenKindName(dep.Kind))
+	}
+	return nil
+}
+
+// Returns the amount the DAppChain balance of the depositor should go up by.
+func (dep *Deposit) expectedIncrease() *big.Int {
+	if dep.Kind == tgtypes.TransferGatewayTokenKind_ERC721 {
+		return big.NewInt(1)
+	}
+	return dep.Amount
+}
+
+// DepositResult describes a deposit that has been credited on the DAppChain.
+type DepositResult struct {
+	// Kind of the deposited tokens, ERC20 deposits of the LOOM token are LOOMCOIN deposits
+	Kind tgtypes.TransferGatewayTokenKind
+	// Ethereum Gateway the tokens were deposited to
+	Gateway common.Address
+	// Latest Ethereum block once the deposit txs have been mined, the deposit was mined in this block
+	// or an earlier one. The confirmations the Oracle waits for are counted from this block.
+	MinedByBlock uint64
+	// DAppChain balance of the depositor before & after the deposit, for ERC721X tokens this is the
+	// balance of the deposited token ID
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+}
+
+// Depositor deposits tokens from Ethereum to the DAppChain. LOOM is deposited via the Ethereum &
+// DAppChain LOOM Gateways, all other tokens via the main Gateways.
+type Depositor struct {
+	LoomClient       *loomclient.DAppChainRPCClient
+	EthClient        *ethclient.Client
+	DAppChainGateway *gw.DAppChainGateway
+	MainnetGateway   *gw.MainnetGatewayClient
+	// Only needed for LOOM deposits
+	DAppChainLoomGateway *gw.DAppChainGateway
+	MainnetLoomGateway   *gw.MainnetGatewayClient
+	// Number of blocks the Oracle waits for before it processes a deposit
+	Confirmations int
+	// Max time to wait for each Ethereum tx to be mined
+	TxTimeout time.Duration
+	// How often to check if the deposit has been confirmed & processed by the Oracle, 5 seconds if
+	// zero
+	PollInterval time.Duration
+	// Max time to wait for the confirmations, and then for the Oracle to process the deposit, 5
+	// minutes if zero
+	Timeout time.Duration
+}
+
+// Returns the address of the LOOM token used by the Ethereum Gateway.
+func (d *Depositor) loomTokenAddress() (common.Address, error) {
+	contract, err := ethcontract.NewMainnetGatewayContract(d.MainnetGateway.Address, d.EthClient)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to connect to Ethereum Gateway")
+	}
+	addr, err := contract.LoomAddress(nil)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to fetch LOOM token address")
+	}
+	return addr, nil
+}
+
+// Route resolves the kind of the deposit, and the Gateways it must be sent to. ERC20 deposits of
+// the LOOM token are LOOM deposits, and must go through the LOOM Gateways.
+func (d *Depositor) Route(dep *Deposit) (tgtypes.TransferGatewayTokenKind, *gw.DAppChainGateway, *gw.MainnetGatewayClient, error) {
+	kind := dep.Kind
+	if kind == tgtypes.TransferGatewayTokenKind_ERC20 || kind == tgtypes.TransferGatewayTokenKind_LOOMCOIN {
+		loomAddr, err := d.loomTokenAddress()
+		if err != nil {
+			return kind, nil, nil, err
+		}
+		if kind == tgtypes.TransferGatewayTokenKind_LOOMCOIN && dep.TokenContract == (common.Address{}) {
+			dep.TokenContract = loomAddr
+		}
+		if dep.TokenContract == loomAddr {
+			kind = tgtypes.TransferGatewayTokenKind_LOOMCOIN
+		} else if kind == tgtypes.TransferGatewayTokenKind_LOOMCOIN {
+			return kind, nil, nil, errors.Errorf("%s is not the LOOM token %s", dep.TokenContract.Hex(), loomAddr.Hex())
+		}
+	}
+	if kind != tgtypes.TransferGatewayTokenKind_LOOMCOIN {
+		return kind, d.DAppChainGateway, d.MainnetGateway, nil
+	}
+	if d.DAppChainLoomGateway == nil || d.MainnetLoomGateway == nil {
+		return kind, nil, nil, errors.New("LOOM deposits require the LOOM Gateways")
+	}
+	return kind, d.DAppChainLoomGateway, d.MainnetLoomGateway, nil
+}
+
+// Returns the DAppChain balance of the depositor.
+func (d *Depositor) dappchainBalance(
+	owner *loomclient.Identity, kind tgtypes.TransferGatewayTokenKind, dep *Deposit,
+) (*big.Int, error) {
+	var balance *big.Int
+	var err error
+	switch kind {
+	case tgtypes.TransferGatewayTokenKind_ETH:
+		var coin *native_coin.DAppChainNativeCoin
+		if coin, err = native_coin.ConnectToDAppChainETHContract(d.LoomClient); err == nil {
+			balance, err = coin.BalanceOf(owner)
+		}
+	case tgtypes.TransferGatewayTokenKind_LOOMCOIN:
+		var coin *native_coin.DAppChainNativeCoin
+		if coin, err = native_coin.ConnectToDAppChainLoomContract(d.LoomClient); err == nil {
+			balance, err = coin.BalanceOf(owner)
+		}
+	case tgtypes.TransferGatewayTokenKind_ERC20:
+		token := &erc20.DAppChainERC20Contract{MirroredTokenContract: dep.DAppChainToken}
+		balance, err = token.BalanceOf(owner)
+	case tgtypes.TransferGatewayTokenKind_ERC721:
+		token := &erc721.DAppChainERC721Contract{MirroredTokenContract: dep.DAppChainToken}
+		balance, err = token.BalanceOf(owner)
+	case tgtypes.TransferGatewayTokenKind_ERC721X:
+		token := &erc721x.DAppChainERC721XContract{MirroredTokenContract: dep.DAppChainToken}
+		balance, err = token.BalanceOf(owner, dep.TokenID)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch DAppChain %s balance", TokenKindName(kind))
+	}
+	return balance, nil
+}
+
+// Sends the txs that move the tokens into the Ethereum Gateway.
+func (d *Depositor) send(
+	owner *loomclient.Identity, kind tgtypes.TransferGatewayTokenKind, dep *Deposit,
+	dappchainGateway *gw.DAppChainGateway, mainnetGateway *gw.MainnetGatewayClient,
+) error {
+	tokenAddr := dep.TokenContract.Hex()
+	switch kind {
+	case tgtypes.TransferGatewayTokenKind_ETH:
+		_, err := mainnetGateway.DepositETH(owner, dep.Amount)
+		return errors.Wrap(err, "failed to deposit ETH")
+
+	case tgtypes.TransferGatewayTokenKind_ERC20, tgtypes.TransferGatewayTokenKind_LOOMCOIN:
+		token, err := client.ConnectToMainnetERC20Contract(d.EthClient, tokenAddr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to ERC20 contract %s", tokenAddr)
+		}
+		token.TxTimeout = d.TxTimeout
+		if dep.HotWallet {
+			res, err := token.TransferTo(owner, mainnetGateway.Address, dep.Amount)
+			if err != nil {
+				return errors.Wrap(err, "failed to transfer tokens to Ethereum Gateway")
+			}
+			err = dappchainGateway.SubmitHotWalletDepositTxHash(owner, res.TxHash)
+			return errors.Wrapf(err, "failed to submit hot wallet deposit tx %s", res.TxHash.Hex())
+		}
+		if _, err := token.Approve(owner, mainnetGateway.Address, dep.Amount); err != nil {
+			return errors.Wrap(err, "failed to approve Ethereum Gateway")
+		}
+		err = mainnetGateway.DepositERC20(owner, dep.Amount, dep.TokenContract)
+		return errors.Wrapf(err, "failed to deposit %s", TokenKindName(kind))
+
+	case tgtypes.TransferGatewayTokenKind_ERC721:
+		safeTransfer, err := d.UsesSafeTransfer(dep)
+		if err != nil {
+			return err
+		}
+		if safeTransfer {
+			token, err := client.ConnectToMainnetERC721MintableContract(d.EthClient, tokenAddr)
+			if err != nil {
+				return errors.Wrapf(err, "failed to connect to ERC721 contract %s", tokenAddr)
+			}
+			token.TxTimeout = d.TxTimeout
+			_, err = token.SafeTransferFrom(owner, mainnetGateway.Address, dep.TokenID)
+			return errors.Wrap(err, "failed to transfer ERC721 token to Ethereum Gateway")
+		}
+		token, err := client.ConnectToMainnetCards(d.EthClient, tokenAddr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to ERC721 contract %s", tokenAddr)
+		}
+		token.TxTimeout = d.TxTimeout
+		_, err = token.DepositToGateway(owner, dep.TokenID)
+		return errors.Wrap(err, "failed to deposit ERC721 token")
+
+	case tgtypes.TransferGatewayTokenKind_ERC721X:
+		token, err := client.ConnectToMainnetERC721XContract(d.EthClient, tokenAddr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to ERC721X contract %s", tokenAddr)
+		}
+		token.TxTimeout = d.TxTimeout
+		_, err = token.DepositToGateway(owner, dep.TokenID, dep.Amount)
+		return errors.Wrap(err, "failed to deposit ERC721X tokens")
+	}
+	return errors.Errorf("unsupported token kind %v", kind)
+}
+
+// Selector of depositToGateway(uint256), which ERC721 contracts like the crypto cards implement to
+// send tokens to the Gateway.
+var depositToGatewaySelector = crypto.Keccak256([]byte("depositToGateway(uint256)"))[:4]
+
+// UsesSafeTransfer returns true if the ERC721 token will be sent to the Ethereum Gateway with
+// safeTransferFrom rather than with depositToGateway, which is the case if the deposit asks for it
+// or the token contract doesn't implement depositToGateway. Solidity contracts look up the method to
+// call by the selectors in their code, so a contract implements the method if its code has the
+// selector.
+func (d *Depositor) UsesSafeTransfer(dep *Deposit) (bool, error) {
+	if dep.SafeTransfer {
+		return true, nil
+	}
+	code, err := d.EthClient.CodeAt(context.TODO(), dep.TokenContract, nil)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to fetch code of ERC721 contract %s", dep.TokenContract.Hex())
+	}
+	if len(code) == 0 {
+		return false, errors.Errorf("no contract deployed at %s", dep.TokenContract.Hex())
+	}
+	return !bytes.Contains(code, depositToGatewaySelector), nil
+}
+
+func (d *Depositor) latestBlock() (uint64, error) {
+	header, err := d.EthClient.HeaderByNumber(context.TODO(), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to fetch latest Ethereum block")
+	}
+	return header.Number.Uint64(), nil
+}
+
+// Deposit sends the tokens to the Ethereum Gateway, waits for the deposit to get the number of
+// confirmations the Oracle waits for, and then for the Oracle to credit the tokens to the owner on
+// the DAppChain. An error is returned if the DAppChain balance of the owner doesn't go up by the
+// deposited amount before the timeout.
+func (d *Depositor) Deposit(owner *loomclient.Identity, dep *Deposit) (*DepositResult, error) {
+	if err := dep.validate(); err != nil {
+		return nil, err
+	}
+	if d.PollInterval == 0 {
+		d.PollInterval = 5 * time.Second
+	}
+	if d.Timeout == 0 {
+		d.Timeout = 5 * time.Minute
+	}
+	kind, dappchainGateway, mainnetGateway, err := d.Route(dep)
+	if err != nil {
+		return nil, err
+	}
+	// Only checked once the deposit has been routed, since LOOM deposits don't need it
+	if _, ok := tokenKindContracts[kind]; ok && dep.DAppChainToken == nil {
+		return nil, errors.Errorf("%s deposit requires a DAppChain token contract", TokenKindName(kind))
+	}
+	result := &DepositResult{Kind: kind, Gateway: mainnetGateway.Address}
+	if result.BalanceBefore, err = d.dappchainBalance(owner, kind, dep); err != nil {
+		return nil, err
+	}
+
+	if err := d.send(owner, kind, dep, dappchainGateway, mainnetGateway); err != nil {
+		return nil, err
+	}
+	// The deposit txs have been mined by now, so the latest block is at least the deposit block
+	if result.MinedByBlock, err = d.latestBlock(); err != nil {
+		return nil, err
+	}
+
+	confirmedBlock := result.MinedByBlock + uint64(d.Confirmations)
+	deadline := time.Now().Add(d.Timeout)
+	for {
+		latest, err := d.latestBlock()
+		if err != nil {
+			return nil, err
+		}
+		if latest >= confirmedBlock {
+			break
+		}
+		if time.Now().After(deadline) {
+			return result, errors.Errorf("timed out waiting for block %d to get %d confirmations, latest block is %d",
+				result.MinedByBlock, d.Confirmations, latest)
+		}
+		time.Sleep(d.PollInterval)
+	}
+
+	expected := new(big.Int).Add(result.BalanceBefore, dep.expectedIncrease())
+	deadline = time.Now().Add(d.Timeout)
+	for {
+		if result.BalanceAfter, err = d.dappchainBalance(owner, kind, dep); err != nil {
+			return result, err
+		}
+		if result.BalanceAfter.Cmp(expected) >= 0 {
+			return result, nil
+		}
+		if time.Now().After(deadline) {
+			return result, errors.Errorf(
+				"timed out waiting for the Oracle to credit the %s deposit, DAppChain balance is %v, expected %v",
+				TokenKindName(kind), result.BalanceAfter, expected)
+		}
+		time.Sleep(d.PollInterval)
+	}
+}
@@ -72,12 +72,9 @@ func GetTestAccountKey(name string) string {
 	return cfg.GetString(name)
 }
 
-func GetMainnetContractCfgString(name string) string {
-	cfg, err := parseConfig("contracts")
-	if err != nil {
-		log.Fatalf("failed to load config file: %v", err)
-	}
-	return cfg.GetString(name)
+// Loads contracts.yml from the e2e config dir
+func GetDeploymentInfo() (*DeploymentInfo, error) {
+	return LoadDeploymentInfo(filepath.Join(GetConfigDir(), "contracts.yml"))
 }
 
 func LoadDAppChainContractABI(contractName string) (*abi.ABI, error) {
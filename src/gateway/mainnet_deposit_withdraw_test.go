@@ -125,8 +125,27 @@ func (s *TransferGatewayTestSuite) SetupSuite() {
 	s.loomEth, err = native_coin.ConnectToDAppChainETHContract(s.loomClient)
 	require.NoError(err)
 
+	deploymentInfo, err := GetDeploymentInfo()
+	require.NoError(err)
+	require.NoError(deploymentInfo.Require(
+		"loomchain_SampleERC20Token_1",
+		"loomchain_SampleERC20Token_2",
+		"loomchain_crypto_cards_addr",
+		"loomchain_SampleERC721XToken_1",
+		"loomchain_erc721_mintable_token_addr",
+		"mainnet_validatorManagerContract_addr",
+		"mainnet_gateway_addr",
+		"mainnet_loomGateway_addr",
+		"mainnet_crypto_cards_addr",
+		"mainnet_erc721_mintable_token_addr",
+		"mainnet_erc721x_cards_addr",
+		"mainnet_game_token_addr",
+		"mainnet_erc20_mintable_token_addr",
+		"loomtoken_addr",
+	))
+
 	// erc20 token
-	dapptokenaddr := GetMainnetContractCfgString("loomchain_SampleERC20Token_1")
+	dapptokenaddr := deploymentInfo.LoomchainERC20TokenAddr
 	mirroredErc20TokenContract, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleERC20Token.abi",
 		"SampleERC20Token", loom.MustParseAddress("default:"+dapptokenaddr))
 	require.NoError(err)
@@ -134,28 +153,28 @@ func (s *TransferGatewayTestSuite) SetupSuite() {
 	require.NoError(err)
 
 	// new mintable token
-	dapptokenaddr = GetMainnetContractCfgString("loomchain_SampleERC20Token_2")
+	dapptokenaddr = deploymentInfo.LoomchainERC20Token2Addr
 	mirroredErc20TokenContract2, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleERC20Token.abi",
 		"SampleERC20Token", loom.MustParseAddress("default:"+dapptokenaddr))
 	require.NoError(err)
 	s.loomERC20_2 = &erc20.DAppChainERC20Contract{MirroredTokenContract: mirroredErc20TokenContract2}
 	require.NoError(err)
 
-	dappeErc721tokenaddr := GetMainnetContractCfgString("loomchain_crypto_cards_addr")
+	dappeErc721tokenaddr := deploymentInfo.LoomchainCryptoCardsAddr
 	mirroredErc721TokenContract, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleERC721Token.abi",
 		"SampleERC721Token", loom.MustParseAddress("default:"+dappeErc721tokenaddr))
 	require.NoError(err)
 	s.loomERC721 = &erc721.DAppChainERC721Contract{MirroredTokenContract: mirroredErc721TokenContract}
 	require.NoError(err)
 
-	dappeErc721xTokenaddr := GetMainnetContractCfgString("loomchain_SampleERC721XToken_1")
+	dappeErc721xTokenaddr := deploymentInfo.LoomchainERC721XTokenAddr
 	mirroredErc721XTokenContract, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleERC721XToken.abi",
 		"SampleERC721XToken", loom.MustParseAddress("default:"+dappeErc721xTokenaddr))
 	require.NoError(err)
 	s.loomERC721X = &erc721x.DAppChainERC721XContract{MirroredTokenContract: mirroredErc721XTokenContract}
 	require.NoError(err)
 
-	dappeErc721mintableTokenaddr := GetMainnetContractCfgString("loomchain_erc721_mintable_token_addr")
+	dappeErc721mintableTokenaddr := deploymentInfo.LoomchainERC721MintableTokenAddr
 	mirroredErc721mintableTokenContract, err := ConnectToTokenContractByAddress(s.loomClient, "../ethcontract/SampleERC721Token.abi",
 		"SampleERC721Token", loom.MustParseAddress("default:"+dappeErc721mintableTokenaddr))
 	require.NoError(err)
@@ -164,39 +183,39 @@ func (s *TransferGatewayTestSuite) SetupSuite() {
 
 	// Connect mainnet contracts
 
-	vmcAddr := GetMainnetContractCfgString("mainnet_validatormanagercontract_addr")
+	vmcAddr := deploymentInfo.MainnetVMCAddr
 	s.validatorsManager, err = vmc.ConnectToMainnetVMCClient(s.ethClient, vmcAddr)
 	require.NoError(err)
 
-	mainnetGatewayAddr := GetMainnetContractCfgString("mainnet_gateway_addr")
+	mainnetGatewayAddr := deploymentInfo.MainnetGatewayAddr
 	s.mainnetGateway, err = gw.ConnectToMainnetGateway(s.ethClient, mainnetGatewayAddr)
 	require.NoError(err)
 
-	mainnetLoomGatewayAddr := GetMainnetContractCfgString("mainnet_loomgateway_addr")
+	mainnetLoomGatewayAddr := deploymentInfo.MainnetLoomGatewayAddr
 	s.mainnetLoomGateway, err = gw.ConnectToMainnetGateway(s.ethClient, mainnetLoomGatewayAddr)
 	require.NoError(err)
 
-	erc721Addr := GetMainnetContractCfgString("mainnet_crypto_cards_addr")
+	erc721Addr := deploymentInfo.MainnetCryptoCardsAddr
 	s.mainnetCards, err = client.ConnectToMainnetCards(s.ethClient, erc721Addr)
 	require.NoError(err)
 
-	erc721Addr2 := GetMainnetContractCfgString("mainnet_erc721_mintable_token_addr")
+	erc721Addr2 := deploymentInfo.MainnetERC721MintableTokenAddr
 	s.mainnetERC721, err = client.ConnectToMainnetERC721MintableContract(s.ethClient, erc721Addr2)
 	require.NoError(err)
 
-	erc721XAddr := GetMainnetContractCfgString("mainnet_erc721x_cards_addr")
+	erc721XAddr := deploymentInfo.MainnetERC721XCardsAddr
 	s.mainnetERC721X, err = client.ConnectToMainnetERC721XContract(s.ethClient, erc721XAddr)
 	require.NoError(err)
 
-	erc20Addr := GetMainnetContractCfgString("mainnet_game_token_addr")
+	erc20Addr := deploymentInfo.MainnetGameTokenAddr
 	s.mainnetCoin, err = client.ConnectToMainnetERC20Contract(s.ethClient, erc20Addr)
 	require.NoError(err)
 
-	erc20Addr2 := GetMainnetContractCfgString("mainnet_erc20_mintable_token_addr")
+	erc20Addr2 := deploymentInfo.MainnetERC20MintableTokenAddr
 	s.mainnetCoin2, err = client.ConnectToMainnetERC20MintableContract(s.ethClient, erc20Addr2)
 	require.NoError(err)
 
-	loomAddr := GetMainnetContractCfgString("loomtoken_addr")
+	loomAddr := deploymentInfo.LoomTokenAddr
 	s.mainnetLoomCoin, err = client.ConnectToMainnetERC20Contract(s.ethClient, loomAddr)
 	require.NoError(err)
 
@@ -255,14 +274,16 @@ func (s *TransferGatewayTestSuite) TestERC721DepositAndWithdraw() {
 	require.NoError(err)
 
 	// Give Alice some ERC721 tokens on Mainnet
-	require.NoError(s.mainnetCards.MintTokens(s.cardsCreator, alice))
+	_, err = s.mainnetCards.MintTokens(s.cardsCreator, alice)
+	require.NoError(err)
 	aliceMainnetCardStartBal, err := s.mainnetCards.BalanceOf(alice)
 	require.NoError(err)
 
 	// Alice deposits one of her tokens to the Mainnet Gateway contract
 	aliceTokenID, err := s.mainnetCards.TokenOfOwnerByIndex(alice, 0)
 	require.NoError(err)
-	require.NoError(s.mainnetCards.DepositToGateway(alice, aliceTokenID))
+	_, err = s.mainnetCards.DepositToGateway(alice, aliceTokenID)
+	require.NoError(err)
 	curBalance, err := s.mainnetCards.BalanceOf(alice)
 	require.NoError(err)
 	require.Equal(aliceMainnetCardStartBal-1, curBalance)
@@ -363,14 +384,16 @@ func (s *TransferGatewayTestSuite) TestERC721DepositTransferWithdraw() {
 	require.NoError(err)
 
 	// Give Alice some ERC721 tokens on Mainnet
-	require.NoError(s.mainnetCards.MintTokens(s.cardsCreator, alice))
+	_, err = s.mainnetCards.MintTokens(s.cardsCreator, alice)
+	require.NoError(err)
 	aliceMainnetCardStartBal, err := s.mainnetCards.BalanceOf(alice)
 	require.NoError(err)
 
 	// Alice deposits one of her tokens to the Mainnet Gateway contract
 	aliceTokenID, err := s.mainnetCards.TokenOfOwnerByIndex(alice, 0)
 	require.NoError(err)
-	require.NoError(s.mainnetCards.DepositToGateway(alice, aliceTokenID))
+	_, err = s.mainnetCards.DepositToGateway(alice, aliceTokenID)
+	require.NoError(err)
 	curBalance, err := s.mainnetCards.BalanceOf(alice)
 	require.NoError(err)
 	require.Equal(aliceMainnetCardStartBal-1, curBalance)
@@ -562,7 +585,8 @@ func (s *TransferGatewayTestSuite) TestERC721XDepositTransferWithdraw() {
 	// Give Alice some ERC721X tokens on Mainnet
 	tokenID := big.NewInt(100)
 	tokenAmt := big.NewInt(5)
-	require.NoError(s.mainnetERC721X.MintTokens(s.cardsCreator, tokenID, tokenAmt, alice))
+	_, err = s.mainnetERC721X.MintTokens(s.cardsCreator, tokenID, tokenAmt, alice)
+	require.NoError(err)
 	aliceMainnetERC721XStartBal, err := s.mainnetERC721X.BalanceOf(alice, tokenID)
 	require.NoError(err)
 	mainnetGatewayStartBal, err := s.mainnetGateway.ERC721XBalance(tokenID, s.mainnetERC721X.Address)
@@ -571,7 +595,8 @@ func (s *TransferGatewayTestSuite) TestERC721XDepositTransferWithdraw() {
 	require.NoError(err)
 
 	// Alice deposits some of her tokens to the Mainnet Gateway contract
-	require.NoError(s.mainnetERC721X.DepositToGateway(alice, tokenID, tokenAmt))
+	_, err = s.mainnetERC721X.DepositToGateway(alice, tokenID, tokenAmt)
+	require.NoError(err)
 	depositedAmt, err := s.mainnetGateway.ERC721XBalance(tokenID, s.mainnetERC721X.Address)
 	require.NoError(err)
 	require.Equal(
@@ -703,7 +728,8 @@ func (s *TransferGatewayTestSuite) TestLoomDepositAndWithdraw() {
 
 	// Give Alice some Loom tokens on Mainnet
 	tokenAmount := sciNot(420)
-	require.NoError(s.mainnetLoomCoin.Transfer(alice, s.coinCreator, tokenAmount))
+	_, err = s.mainnetLoomCoin.Transfer(alice, s.coinCreator, tokenAmount)
+	require.NoError(err)
 	aliceMainnetLoomCoinStartBal, err := s.mainnetLoomCoin.BalanceOf(alice)
 	fmt.Println("ALICE MAINNET BALANCE", aliceMainnetLoomCoinStartBal)
 	require.NoError(err)
@@ -714,7 +740,8 @@ func (s *TransferGatewayTestSuite) TestLoomDepositAndWithdraw() {
 	require.NoError(err)
 
 	// Alice deposits her tokens into the Mainnet Gateway contract
-	require.NoError(s.mainnetLoomCoin.Approve(alice, s.mainnetLoomGateway.Address, tokenAmount))
+	_, err = s.mainnetLoomCoin.Approve(alice, s.mainnetLoomGateway.Address, tokenAmount)
+	require.NoError(err)
 	require.NoError(s.mainnetLoomGateway.DepositERC20(alice, tokenAmount, s.mainnetLoomCoin.Address))
 	curBalance, err := s.mainnetLoomCoin.BalanceOf(alice)
 	fmt.Println("ALICE MAINNET BALANCE AFTER DEPOSIT", curBalance)
@@ -818,7 +845,8 @@ func (s *TransferGatewayTestSuite) TestERC20DepositAndWithdraw() {
 
 	// Give Alice some ERC20 tokens on Mainnet
 	tokenAmount := sciNot(157)
-	require.NoError(s.mainnetCoin.Transfer(alice, s.coinCreator, tokenAmount))
+	_, err = s.mainnetCoin.Transfer(alice, s.coinCreator, tokenAmount)
+	require.NoError(err)
 	aliceMainnetCoinStartBal, err := s.mainnetCoin.BalanceOf(alice)
 	require.NoError(err)
 	mainnetGatewayStartBal, err := s.mainnetGateway.ERC20Balance(s.mainnetCoin.Address)
@@ -827,7 +855,8 @@ func (s *TransferGatewayTestSuite) TestERC20DepositAndWithdraw() {
 	require.NoError(err)
 
 	// Alice deposits her tokens into the Mainnet Gateway contract
-	require.NoError(s.mainnetCoin.Approve(alice, s.mainnetGateway.Address, tokenAmount))
+	_, err = s.mainnetCoin.Approve(alice, s.mainnetGateway.Address, tokenAmount)
+	require.NoError(err)
 	require.NoError(s.mainnetGateway.DepositERC20(alice, tokenAmount, s.mainnetCoin.Address))
 	curBalance, err := s.mainnetCoin.BalanceOf(alice)
 	require.NoError(err)
@@ -1527,7 +1556,8 @@ func (s *TransferGatewayTestSuite) TestLoomCoinWithdrawalLimit() {
 	// current max per account withdrawal amount is 500,000
 	amount := sciNot(2000000)
 
-	require.NoError(s.mainnetLoomCoin.Transfer(alice, s.coinCreator, amount))
+	_, err = s.mainnetLoomCoin.Transfer(alice, s.coinCreator, amount)
+	require.NoError(err)
 	aliceMainnetEthStartBal, err := s.mainnetLoomCoin.BalanceOf(alice)
 	require.NoError(err)
 	gatewayMainnetLoomCoinStartBal, err := s.mainnetLoomGateway.ERC20Balance(s.mainnetLoomCoin.Address)
@@ -1536,7 +1566,8 @@ func (s *TransferGatewayTestSuite) TestLoomCoinWithdrawalLimit() {
 	require.NoError(err)
 
 	// Alice deposits some LOOM into the Mainnet Gateway contract
-	require.NoError(s.mainnetLoomCoin.Approve(alice, s.mainnetLoomGateway.Address, amount))
+	_, err = s.mainnetLoomCoin.Approve(alice, s.mainnetLoomGateway.Address, amount)
+	require.NoError(err)
 	require.NoError(s.mainnetLoomGateway.DepositERC20(alice, amount, s.mainnetLoomCoin.Address))
 
 	curBalance, err := s.mainnetLoomCoin.BalanceOf(alice)
@@ -1714,7 +1745,8 @@ func (s *TransferGatewayTestSuite) TestHotWalletERC20DepositWithdraw() {
 	// Give Alice some ERC20 tokens on Mainnet
 	tokenAmount := sciNot(300)
 	tokenAmountHalf := sciNot(150)
-	require.NoError(s.mainnetCoin.Transfer(alice, s.coinCreator, tokenAmount))
+	_, err = s.mainnetCoin.Transfer(alice, s.coinCreator, tokenAmount)
+	require.NoError(err)
 	aliceMainnetCoinStartBal, err := s.mainnetCoin.BalanceOf(alice)
 	require.NoError(err)
 	mainnetGatewayStartBal, err := s.mainnetGateway.ERC20Balance(s.mainnetCoin.Address)
@@ -0,0 +1,528 @@
+// +build evm
+
+package gateway
+
+import (
+	"ethcontract"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/loomnetwork/go-loom"
+	tgtypes "github.com/loomnetwork/go-loom/builtin/types/transfer_gateway"
+	loomclient "github.com/loomnetwork/go-loom/client"
+	"github.com/loomnetwork/go-loom/client/erc20"
+	"github.com/loomnetwork/go-loom/client/erc721"
+	"github.com/loomnetwork/go-loom/client/erc721x"
+	gw "github.com/loomnetwork/go-loom/client/gateway_v2"
+	"github.com/loomnetwork/go-loom/client/native_coin"
+	"github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
+)
+
+var tokenKindNames = map[tgtypes.TransferGatewayTokenKind]string{
+	tgtypes.TransferGatewayTokenKind_ETH:      "eth",
+	tgtypes.TransferGatewayTokenKind_LOOMCOIN: "loomcoin",
+	tgtypes.TransferGatewayTokenKind_ERC20:    "erc20",
+	tgtypes.TransferGatewayTokenKind_ERC721:   "erc721",
+	tgtypes.TransferGatewayTokenKind_ERC721X:  "erc721x",
+}
+
+// Name of the DAppChain contract whose ABI is used to interact with each kind of token.
+var tokenKindContracts = map[tgtypes.TransferGatewayTokenKind]string{
+	tgtypes.TransferGatewayTokenKind_ERC20:   "SampleERC20Token",
+	tgtypes.TransferGatewayTokenKind_ERC721:  "SampleERC721Token",
+	tgtypes.TransferGatewayTokenKind_ERC721X: "SampleERC721XToken",
+}
+
+// ParseTokenKind parses the name of a token kind supported by the Ethereum Gateways, i.e. eth,
+// loomcoin, erc20, erc721, or erc721x.
+func ParseTokenKind(name string) (tgtypes.TransferGatewayTokenKind, error) {
+	for kind, kindName := range tokenKindNames {
+		if kindName == strings.ToLower(name) {
+			return kind, nil
+		}
+	}
+	return 0, errors.Errorf("unsupported token kind %s", name)
+}
+
+// TokenKindName returns the name ParseTokenKind accepts for the given token kind.
+func TokenKindName(kind tgtypes.TransferGatewayTokenKind) string {
+	if name, ok := tokenKindNames[kind]; ok {
+		return name
+	}
+	return kind.String()
+}
+
+// ConnectToDAppChainToken connects to the DAppChain token contract at the given address, using the
+// ABI of the sample contract for the token kind.
+func ConnectToDAppChainToken(
+	loomClient *loomclient.DAppChainRPCClient, kind tgtypes.TransferGatewayTokenKind, addr loom.Address,
+) (*loomclient.MirroredTokenContract, error) {
+	contractName, ok := tokenKindContracts[kind]
+	if !ok {
+		return nil, errors.Errorf("%s tokens don't have a DAppChain token contract", TokenKindName(kind))
+	}
+	contractABI, err := LoadDAppChainContractABI(contractName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s ABI", contractName)
+	}
+	return &loomclient.MirroredTokenContract{
+		Contract:    loomclient.NewEvmContract(loomClient, addr.Local),
+		ContractABI: contractABI,
+		ChainID:     loomClient.GetChainID(),
+		Address:     addr,
+	}, nil
+}
+
+// WithdrawalStage is how far a withdrawal got, the stages are listed in the order they're reached.
+type WithdrawalStage string
+
+const (
+	// Nothing has been sent yet
+	WithdrawalNotStarted WithdrawalStage = "not_started"
+	// The DAppChain Gateway has been approved to take the tokens
+	WithdrawalApproved WithdrawalStage = "approved"
+	// The DAppChain Gateway has created a withdrawal receipt
+	WithdrawalRequested WithdrawalStage = "requested"
+	// The Oracle has signed the withdrawal receipt
+	WithdrawalSigned WithdrawalStage = "signed"
+	// The tokens have been withdrawn from the Ethereum Gateway
+	WithdrawalSubmitted WithdrawalStage = "submitted"
+	// The Oracle has cleared the withdrawal receipt
+	WithdrawalCompleted WithdrawalStage = "completed"
+)
+
+// ErrPendingWithdrawal is returned when a new withdrawal is started while the account has a
+// pending withdrawal receipt for other tokens, which must be completed first.
+var ErrPendingWithdrawal = errors.New("account has a pending withdrawal")
+
+// Withdrawal describes the tokens that should be withdrawn from the DAppChain to Ethereum.
+type Withdrawal struct {
+	Kind tgtypes.TransferGatewayTokenKind
+	// Amount of ETH, LOOM, ERC20, or ERC721X tokens
+	Amount *big.Int
+	// ERC721 or ERC721X token ID
+	TokenID *big.Int
+	// DAppChain token contract, not used for ETH & LOOM
+	TokenContract *loomclient.MirroredTokenContract
+}
+
+func (wd *Withdrawal) validate() error {
+	if _, ok := tokenKindNames[wd.Kind]; !ok {
+		return errors.Errorf("unsupported token kind %v", wd.Kind)
+	}
+	if wd.Kind != tgtypes.TransferGatewayTokenKind_ERC721 && (wd.Amount == nil || wd.Amount.Sign() <= 0) {
+		return errors.Errorf("%s withdrawal requires a positive amount", TokenKindName(wd.Kind))
+	}
+	if (wd.Kind == tgtypes.TransferGatewayTokenKind_ERC721 || wd.Kind == tgtypes.TransferGatewayTokenKind_ERC721X) &&
+		wd.TokenID == nil {
+		return errors.Errorf("%s withdrawal requires a token ID", TokenKindName(wd.Kind))
+	}
+	if _, ok := tokenKindContracts[wd.Kind]; ok && wd.TokenContract == nil {
+		return errors.Errorf("%s withdrawal requires a DAppChain token contract", TokenKindName(wd.Kind))
+	}
+	return nil
+}
+
+// WithdrawalProgress records how far a withdrawal got, so that it can be resumed if the process
+// running it is interrupted.
+type WithdrawalProgress struct {
+	Stage   WithdrawalStage `json:"stage"`
+	Owner   string          `json:"owner"`
+	Kind    string          `json:"kind"`
+	Amount  *big.Int        `json:"amount,omitempty"`
+	TokenID *big.Int        `json:"token_id,omitempty"`
+	// Local address of the DAppChain token contract
+	TokenContract string `json:"token_contract,omitempty"`
+	// Nonce of the withdrawal receipt, set once the DAppChain Gateway has created the receipt
+	WithdrawalNonce *uint64   `json:"withdrawal_nonce,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// NewWithdrawalProgress returns the progress of a withdrawal that hasn't been started yet.
+func NewWithdrawalProgress(owner *loomclient.Identity, wd *Withdrawal) *WithdrawalProgress {
+	p := &WithdrawalProgress{
+		Stage:   WithdrawalNotStarted,
+		Owner:   owner.MainnetAddr.Hex(),
+		Kind:    TokenKindName(wd.Kind),
+		Amount:  wd.Amount,
+		TokenID: wd.TokenID,
+	}
+	if wd.TokenContract != nil {
+		p.TokenContract = wd.TokenContract.Address.Local.Hex()
+	}
+	return p
+}
+
+// Checks that the progress was recorded for the same withdrawal.
+func (p *WithdrawalProgress) matches(other *WithdrawalProgress) bool {
+	return p.Owner == other.Owner && p.Kind == other.Kind && p.TokenContract == other.TokenContract &&
+		bigIntsEqual(p.Amount, other.Amount) && bigIntsEqual(p.TokenID, other.TokenID)
+}
+
+func bigIntsEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
+
+// PendingWithdrawal is a withdrawal receipt stored in the DAppChain Gateway, along with the state
+// of the withdrawal on Ethereum.
+type PendingWithdrawal struct {
+	Receipt *tgtypes.TransferGatewayWithdrawalReceipt
+	Kind    tgtypes.TransferGatewayTokenKind
+	// Ethereum token contract, not set for ETH
+	TokenContract common.Address
+	TokenID       *big.Int
+	Amount        *big.Int
+	// Nonce of the account in the Ethereum Gateway, the withdrawal has been completed on Ethereum
+	// once this is greater than the nonce of the receipt.
+	MainnetNonce *big.Int
+}
+
+// Signed returns true if the Oracle has signed the withdrawal receipt.
+func (p *PendingWithdrawal) Signed() bool {
+	return len(p.Receipt.OracleSignature) > 0
+}
+
+// NonceConsumed returns true if the Ethereum Gateway has already accepted the withdrawal.
+func (p *PendingWithdrawal) NonceConsumed() bool {
+	return p.MainnetNonce.Cmp(new(big.Int).SetUint64(p.Receipt.WithdrawalNonce)) > 0
+}
+
+// Stage returns how far the withdrawal got.
+func (p *PendingWithdrawal) Stage() WithdrawalStage {
+	switch {
+	case p.NonceConsumed():
+		return WithdrawalSubmitted
+	case p.Signed():
+		return WithdrawalSigned
+	default:
+		return WithdrawalRequested
+	}
+}
+
+// Matches returns true if the receipt could be for the given withdrawal.
+func (p *PendingWithdrawal) Matches(wd *Withdrawal) bool {
+	if p.Kind != wd.Kind {
+		return false
+	}
+	switch wd.Kind {
+	case tgtypes.TransferGatewayTokenKind_ERC721:
+		return bigIntsEqual(p.TokenID, wd.TokenID)
+	case tgtypes.TransferGatewayTokenKind_ERC721X:
+		return bigIntsEqual(p.TokenID, wd.TokenID) && bigIntsEqual(p.Amount, wd.Amount)
+	default:
+		return bigIntsEqual(p.Amount, wd.Amount)
+	}
+}
+
+func bigUIntValue(v *types.BigUInt) *big.Int {
+	if v == nil || v.Value.Int == nil {
+		return nil
+	}
+	return v.Value.Int
+}
+
+// Withdrawer withdraws tokens from the DAppChain to Ethereum. LOOM must be withdrawn via the
+// DAppChain & Ethereum LOOM Gateways, all other tokens via the main Gateways.
+type Withdrawer struct {
+	LoomClient       *loomclient.DAppChainRPCClient
+	EthClient        *ethclient.Client
+	DAppChainGateway *gw.DAppChainGateway
+	MainnetGateway   *gw.MainnetGatewayClient
+	// How often to check if the Oracle has processed the withdrawal, 5 seconds if zero
+	PollInterval time.Duration
+	// Max time to wait for the Oracle at each stage, 5 minutes if zero
+	Timeout time.Duration
+	// Called every time the withdrawal reaches a new stage, e.g. to save the progress to a file
+	OnProgress func(progress *WithdrawalProgress) error
+}
+
+func (w *Withdrawer) mainnetGatewayContract() (*ethcontract.MainnetGatewayContract, error) {
+	contract, err := ethcontract.NewMainnetGatewayContract(w.MainnetGateway.Address, w.EthClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to Ethereum Gateway")
+	}
+	return contract, nil
+}
+
+func (w *Withdrawer) mainnetNonce(owner *loomclient.Identity) (*big.Int, error) {
+	contract, err := w.mainnetGatewayContract()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := contract.Nonces(nil, owner.MainnetAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch Ethereum Gateway nonce")
+	}
+	return nonce, nil
+}
+
+// Returns true if the Ethereum Gateway has accepted the withdrawal with the given nonce.
+func (w *Withdrawer) nonceConsumed(owner *loomclient.Identity, withdrawalNonce uint64) (bool, error) {
+	nonce, err := w.mainnetNonce(owner)
+	if err != nil {
+		return false, err
+	}
+	return nonce.Cmp(new(big.Int).SetUint64(withdrawalNonce)) > 0, nil
+}
+
+// PendingWithdrawal returns the pending withdrawal of the given account, or nil if the account
+// doesn't have one.
+func (w *Withdrawer) PendingWithdrawal(owner *loomclient.Identity) (*PendingWithdrawal, error) {
+	receipt, err := w.DAppChainGateway.WithdrawalReceipt(owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch withdrawal receipt")
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+	nonce, err := w.mainnetNonce(owner)
+	if err != nil {
+		return nil, err
+	}
+	p := &PendingWithdrawal{
+		Receipt:      receipt,
+		Kind:         receipt.TokenKind,
+		TokenID:      bigUIntValue(receipt.TokenID),
+		Amount:       bigUIntValue(receipt.TokenAmount),
+		MainnetNonce: nonce,
+	}
+	if receipt.TokenContract != nil {
+		p.TokenContract = common.BytesToAddress(loom.UnmarshalAddressPB(receipt.TokenContract).Local)
+	}
+	return p, nil
+}
+
+// CompleteWithdrawal withdraws the tokens in the signed withdrawal receipt from the Ethereum
+// Gateway.
+func (w *Withdrawer) CompleteWithdrawal(owner *loomclient.Identity, p *PendingWithdrawal) error {
+	if !p.Signed() {
+		return errors.New("withdrawal receipt hasn't been signed by the Oracle yet")
+	}
+	if p.NonceConsumed() {
+		return errors.New("withdrawal has already been completed on Ethereum")
+	}
+	contract, err := w.mainnetGatewayContract()
+	if err != nil {
+		return err
+	}
+	vmcAddr, err := contract.Vmc(nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch Validator Manager Contract address")
+	}
+	vmc, err := ethcontract.NewValidatorManagerContract(vmcAddr, w.EthClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Validator Manager Contract")
+	}
+	validators, err := vmc.GetValidators(nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch validators")
+	}
+
+	sig := p.Receipt.OracleSignature
+	switch p.Kind {
+	case tgtypes.TransferGatewayTokenKind_ETH:
+		_, err = w.MainnetGateway.WithdrawETH(owner, p.Amount, sig, validators)
+	case tgtypes.TransferGatewayTokenKind_ERC20, tgtypes.TransferGatewayTokenKind_LOOMCOIN:
+		err = w.MainnetGateway.WithdrawERC20(owner, p.Amount, p.TokenContract, sig, validators)
+	case tgtypes.TransferGatewayTokenKind_ERC721:
+		err = w.MainnetGateway.WithdrawERC721(owner, p.TokenID, p.TokenContract, sig, validators)
+	case tgtypes.TransferGatewayTokenKind_ERC721X:
+		err = w.MainnetGateway.WithdrawERC721X(owner, p.TokenID, p.Amount, p.TokenContract, sig, validators)
+	default:
+		return errors.Errorf("unsupported token kind %v", p.Kind)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to withdraw %s from Ethereum Gateway", TokenKindName(p.Kind))
+	}
+	return nil
+}
+
+// Approves the DAppChain Gateway to take the tokens, and asks it to create a withdrawal receipt.
+func (w *Withdrawer) requestWithdrawal(owner *loomclient.Identity, wd *Withdrawal, progress *WithdrawalProgress) error {
+	gatewayAddr := w.DAppChainGateway.Address
+	if progress.Stage == WithdrawalNotStarted {
+		var err error
+		switch wd.Kind {
+		case tgtypes.TransferGatewayTokenKind_ETH:
+			var coin *native_coin.DAppChainNativeCoin
+			if coin, err = native_coin.ConnectToDAppChainETHContract(w.LoomClient); err == nil {
+				err = coin.Approve(owner, gatewayAddr, wd.Amount)
+			}
+		case tgtypes.TransferGatewayTokenKind_LOOMCOIN:
+			var coin *native_coin.DAppChainNativeCoin
+			if coin, err = native_coin.ConnectToDAppChainLoomContract(w.LoomClient); err == nil {
+				err = coin.Approve(owner, gatewayAddr, wd.Amount)
+			}
+		case tgtypes.TransferGatewayTokenKind_ERC20:
+			token := &erc20.DAppChainERC20Contract{MirroredTokenContract: wd.TokenContract}
+			err = token.Approve(owner, gatewayAddr, wd.Amount)
+		case tgtypes.TransferGatewayTokenKind_ERC721:
+			token := &erc721.DAppChainERC721Contract{MirroredTokenContract: wd.TokenContract}
+			err = token.Approve(owner, gatewayAddr, wd.TokenID)
+		case tgtypes.TransferGatewayTokenKind_ERC721X:
+			token := &erc721x.DAppChainERC721XContract{MirroredTokenContract: wd.TokenContract}
+			err = token.SetApprovalForAll(owner, gatewayAddr, true)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to approve DAppChain Gateway")
+		}
+		if err := w.setStage(progress, WithdrawalApproved); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(w.Timeout)
+	for {
+		var err error
+		switch wd.Kind {
+		case tgtypes.TransferGatewayTokenKind_ETH:
+			err = w.DAppChainGateway.WithdrawETH(owner, wd.Amount, w.MainnetGateway.Address)
+		case tgtypes.TransferGatewayTokenKind_LOOMCOIN:
+			var contract *ethcontract.MainnetGatewayContract
+			if contract, err = w.mainnetGatewayContract(); err == nil {
+				var loomAddr common.Address
+				if loomAddr, err = contract.LoomAddress(nil); err == nil {
+					err = w.DAppChainGateway.WithdrawLoom(owner, wd.Amount, loomAddr)
+				}
+			}
+		case tgtypes.TransferGatewayTokenKind_ERC20:
+			err = w.DAppChainGateway.WithdrawERC20(owner, wd.Amount, wd.TokenContract.Address)
+		case tgtypes.TransferGatewayTokenKind_ERC721:
+			err = w.DAppChainGateway.WithdrawERC721(owner, wd.TokenID, wd.TokenContract.Address, nil)
+		case tgtypes.TransferGatewayTokenKind_ERC721X:
+			err = w.DAppChainGateway.WithdrawERC721X(owner, wd.TokenID, wd.Amount, wd.TokenContract.Address, nil)
+		}
+		if err == nil {
+			return nil
+		}
+		// TG003 means the DAppChain Gateway is still processing the previous withdrawal
+		if !strings.Contains(err.Error(), "TG003") || time.Now().After(deadline) {
+			return errors.Wrap(err, "failed to request withdrawal from DAppChain Gateway")
+		}
+		time.Sleep(w.PollInterval)
+	}
+}
+
+func (w *Withdrawer) setStage(progress *WithdrawalProgress, stage WithdrawalStage) error {
+	progress.Stage = stage
+	progress.UpdatedAt = time.Now().UTC()
+	if w.OnProgress == nil {
+		return nil
+	}
+	return w.OnProgress(progress)
+}
+
+// Withdraw runs the withdrawal through all the stages, waiting for the Oracle when needed, until
+// the DAppChain Gateway clears the withdrawal receipt. The given progress is updated as each stage
+// is reached, and if it was recorded by an earlier call that got interrupted the withdrawal is
+// resumed from where it stopped. Waiting for the Oracle can be interrupted at any stage, the
+// withdrawal can be resumed later by passing the same progress.
+func (w *Withdrawer) Withdraw(owner *loomclient.Identity, wd *Withdrawal, progress *WithdrawalProgress) error {
+	if err := wd.validate(); err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = NewWithdrawalProgress(owner, wd)
+	} else if !progress.matches(NewWithdrawalProgress(owner, wd)) {
+		return errors.New("progress was recorded for a different withdrawal")
+	}
+	if progress.Stage == WithdrawalCompleted {
+		return errors.New("withdrawal has already been completed")
+	}
+	if w.PollInterval == 0 {
+		w.PollInterval = 5 * time.Second
+	}
+	if w.Timeout == 0 {
+		w.Timeout = 5 * time.Minute
+	}
+
+	deadline := time.Now().Add(w.Timeout)
+	for {
+		pending, err := w.PendingWithdrawal(owner)
+		if err != nil {
+			return err
+		}
+
+		if pending == nil {
+			switch {
+			case progress.Stage == WithdrawalNotStarted || progress.Stage == WithdrawalApproved:
+				if err := w.requestWithdrawal(owner, wd, progress); err != nil {
+					return err
+				}
+				if err := w.setStage(progress, WithdrawalRequested); err != nil {
+					return err
+				}
+				deadline = time.Now().Add(w.Timeout)
+			case progress.WithdrawalNonce == nil:
+				// The DAppChain Gateway hasn't created the receipt yet
+			default:
+				// The receipt is cleared by the Oracle once the withdrawal has been completed on
+				// Ethereum, which may have happened before the submitted stage was recorded.
+				consumed, err := w.nonceConsumed(owner, *progress.WithdrawalNonce)
+				if err != nil {
+					return err
+				}
+				if !consumed {
+					return errors.Errorf("withdrawal receipt %d disappeared before the withdrawal was completed on Ethereum",
+						*progress.WithdrawalNonce)
+				}
+				return w.setStage(progress, WithdrawalCompleted)
+			}
+		} else {
+			if progress.WithdrawalNonce == nil {
+				// The receipt may belong to a withdrawal that was requested before this one
+				if !pending.Matches(wd) {
+					return errors.Wrapf(ErrPendingWithdrawal, "receipt for %s withdrawal with nonce %d",
+						TokenKindName(pending.Kind), pending.Receipt.WithdrawalNonce)
+				}
+				nonce := pending.Receipt.WithdrawalNonce
+				progress.WithdrawalNonce = &nonce
+				// Record the nonce right away, without it an interrupted withdrawal can't be matched
+				// to its receipt, or found to be completed once the receipt is cleared.
+				if err := w.setStage(progress, pending.Stage()); err != nil {
+					return err
+				}
+				deadline = time.Now().Add(w.Timeout)
+			} else if *progress.WithdrawalNonce != pending.Receipt.WithdrawalNonce {
+				return errors.Wrapf(ErrPendingWithdrawal, "receipt nonce is %d, expected %d",
+					pending.Receipt.WithdrawalNonce, *progress.WithdrawalNonce)
+			}
+
+			stage := pending.Stage()
+			if stage != progress.Stage {
+				if err := w.setStage(progress, stage); err != nil {
+					return err
+				}
+				deadline = time.Now().Add(w.Timeout)
+			}
+			if stage == WithdrawalSigned {
+				if err := w.CompleteWithdrawal(owner, pending); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if time.Now().After(deadline) && progress.WithdrawalNonce != nil {
+			switch progress.Stage {
+			case WithdrawalRequested:
+				return errors.Errorf("timed out waiting for the Oracle to sign withdrawal receipt %d",
+					*progress.WithdrawalNonce)
+			case WithdrawalSubmitted:
+				return errors.Errorf("timed out waiting for the Oracle to clear withdrawal receipt %d",
+					*progress.WithdrawalNonce)
+			}
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("timed out waiting for the DAppChain Gateway to create a withdrawal receipt")
+		}
+		time.Sleep(w.PollInterval)
+	}
+}